@@ -0,0 +1,143 @@
+package collection
+
+import "sort"
+import csha256 "crypto/sha256"
+
+// Enums
+
+const(
+    batchadd = iota
+    batchset
+    batchsetfield
+    batchremove
+)
+
+// operation
+
+type operation struct {
+    kind int
+    key []byte
+    field int
+    values []interface{}
+}
+
+// Batch
+
+type Batch struct {
+    operations []operation
+}
+
+// Getters
+
+func (this Batch) Len() int {
+    return len(this.operations)
+}
+
+// Methods
+
+func (this *Batch) Add(key []byte, values... interface{}) {
+    this.operations = append(this.operations, operation{batchadd, key, 0, values})
+}
+
+func (this *Batch) Set(key []byte, values... interface{}) {
+    this.operations = append(this.operations, operation{batchset, key, 0, values})
+}
+
+func (this *Batch) SetField(key []byte, field int, value interface{}) {
+    this.operations = append(this.operations, operation{batchsetfield, key, field, []interface{}{value}})
+}
+
+func (this *Batch) Remove(key []byte) {
+    this.operations = append(this.operations, operation{batchremove, key, 0, []interface{}{}})
+}
+
+// batchorder
+
+type batchorder struct {
+    indexes []int
+    paths [][csha256.Size]byte
+}
+
+// Interface
+
+func (this batchorder) Len() int {
+    return len(this.indexes)
+}
+
+func (this batchorder) Less(i int, j int) bool {
+    left := this.paths[this.indexes[i]]
+    right := this.paths[this.indexes[j]]
+
+    for index := 0; index < csha256.Size; index++ {
+        if left[index] != right[index] {
+            return left[index] < right[index]
+        }
+    }
+
+    return false
+}
+
+func (this batchorder) Swap(i int, j int) {
+    this.indexes[i], this.indexes[j] = this.indexes[j], this.indexes[i]
+}
+
+// collection
+
+// Methods (collection) (batch)
+
+func (this *collection) ApplyBatch(batch Batch) []error {
+    for _, operation := range(batch.operations) {
+        switch operation.kind {
+        case batchadd, batchset:
+            if len(operation.values) != len(this.fields) {
+                panic("Wrong number of values provided.")
+            }
+        case batchsetfield:
+            if operation.field >= len(this.fields) {
+                panic("Field does not exist.")
+            }
+        }
+    }
+
+    order := batchorder{make([]int, len(batch.operations)), make([][csha256.Size]byte, len(batch.operations))}
+
+    for index := 0; index < len(batch.operations); index++ {
+        order.indexes[index] = index
+        order.paths[index] = this.path(batch.operations[index].key)
+    }
+
+    sort.Stable(order)
+
+    ongoing := this.transaction.ongoing
+
+    if !ongoing {
+        this.Begin()
+    }
+
+    errors := make([]error, len(batch.operations))
+
+    for _, index := range(order.indexes) {
+        operation := batch.operations[index]
+
+        switch operation.kind {
+        case batchadd:
+            errors[index] = this.Add(operation.key, operation.values...)
+        case batchset:
+            errors[index] = this.Set(operation.key, operation.values...)
+        case batchsetfield:
+            errors[index] = this.SetField(operation.key, operation.field, operation.values[0])
+        case batchremove:
+            errors[index] = this.Remove(operation.key)
+        }
+    }
+
+    if !ongoing {
+        this.End()
+
+        if !(this.AutoCollect.value) {
+            this.Collect()
+        }
+    }
+
+    return errors
+}
@@ -0,0 +1,125 @@
+package collection
+
+import "testing"
+
+func TestBatchApply(test *testing.T) {
+    ctx := testctx("[batch.go]", test)
+
+    stake64 := Stake64{}
+    data := Data{}
+
+    sequential := EmptyCollection(stake64, data)
+    batched := EmptyCollection(stake64, data)
+
+    batch := Batch{}
+
+    for index := 0; index < 64; index++ {
+        key := []byte{byte(index)}
+
+        sequential.Add(key, uint64(index), key)
+        batch.Add(key, uint64(index), key)
+    }
+
+    for index := 0; index < 64; index += 3 {
+        sequential.SetField([]byte{byte(index)}, 0, uint64(2 * index))
+        batch.SetField([]byte{byte(index)}, 0, uint64(2 * index))
+    }
+
+    for index := 1; index < 64; index += 4 {
+        sequential.Remove([]byte{byte(index)})
+        batch.Remove([]byte{byte(index)})
+    }
+
+    sequential.Set([]byte{2}, uint64(1), []byte("two"))
+    batch.Set([]byte{2}, uint64(1), []byte("two"))
+
+    if batch.Len() != 64 + 22 + 16 + 1 {
+        test.Error("[batch.go]", "[len]", "Len() does not count the operations.")
+    }
+
+    errors := batched.ApplyBatch(batch)
+
+    if len(errors) != batch.Len() {
+        test.Error("[batch.go]", "[apply]", "ApplyBatch() does not return an error slot for each operation.")
+    }
+
+    for _, error := range(errors) {
+        if error != nil {
+            test.Error("[batch.go]", "[apply]", "ApplyBatch() yields an error on a valid operation.")
+        }
+    }
+
+    if batched.root.label != sequential.root.label {
+        test.Error("[batch.go]", "[apply]", "ApplyBatch() does not yield the same root as sequential manipulation.")
+    }
+
+    if batched.transaction.ongoing {
+        test.Error("[batch.go]", "[apply]", "ApplyBatch() leaves a transaction ongoing.")
+    }
+
+    ctx.verify.tree("[apply]", &batched)
+    ctx.verify.values("[apply]", &batched, []byte{2}, uint64(1), []byte("two"))
+    ctx.verify.values("[apply]", &batched, []byte{3}, uint64(6), []byte{3})
+    ctx.verify.nokey("[apply]", &batched, []byte{5})
+
+    ctx.should_panic("[values]", func() {
+        wrong := Batch{}
+        wrong.Add([]byte("key"), uint64(1))
+        batched.ApplyBatch(wrong)
+    })
+
+    ctx.should_panic("[field]", func() {
+        wrong := Batch{}
+        wrong.SetField([]byte("key"), 2, uint64(1))
+        batched.ApplyBatch(wrong)
+    })
+
+    if batched.transaction.ongoing {
+        test.Error("[batch.go]", "[panic]", "ApplyBatch() begins a transaction before validating the batch.")
+    }
+}
+
+func TestBatchErrors(test *testing.T) {
+    ctx := testctx("[batch.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    collection.Add([]byte("alice"), uint64(10))
+
+    batch := Batch{}
+
+    batch.Add([]byte("alice"), uint64(11))
+    batch.Add([]byte("bob"), uint64(20))
+    batch.Set([]byte("carol"), uint64(30))
+    batch.Remove([]byte("dave"))
+    batch.Remove([]byte("bob"))
+    batch.Add([]byte("bob"), uint64(21))
+
+    errors := collection.ApplyBatch(batch)
+
+    for index, failing := range([]bool{true, false, true, true, false, false}) {
+        if (errors[index] != nil) != failing {
+            test.Error("[batch.go]", "[errors]", "ApplyBatch() does not report the error of each operation at its position.")
+        }
+    }
+
+    ctx.verify.values("[errors]", &collection, []byte("alice"), uint64(10))
+    ctx.verify.values("[errors]", &collection, []byte("bob"), uint64(21))
+    ctx.verify.tree("[errors]", &collection)
+
+    collection.Begin()
+
+    batch = Batch{}
+    batch.Set([]byte("alice"), uint64(12))
+
+    collection.ApplyBatch(batch)
+
+    if !(collection.transaction.ongoing) {
+        test.Error("[batch.go]", "[transaction]", "ApplyBatch() ends a transaction it did not begin.")
+    }
+
+    collection.Rollback()
+
+    ctx.verify.values("[transaction]", &collection, []byte("alice"), uint64(10))
+}
@@ -0,0 +1,89 @@
+package collection
+
+import "errors"
+import "strconv"
+
+// BlockError
+
+type BlockError struct {
+    Index int
+    Cause error
+}
+
+// Interface
+
+func (this BlockError) Error() string {
+    return "Update " + strconv.Itoa(this.Index) + " of block failed: " + this.Cause.Error()
+}
+
+// collection
+
+// Methods (collection) (block)
+
+func (this *collection) ApplyBlock(updates... interface{}) (ChangeSet, error) {
+    if this.transaction.ongoing {
+        panic("Cannot apply a block while a transaction is ongoing.")
+    }
+
+    prepared := make([]Update, len(updates))
+
+    for index := 0; index < len(updates); index++ {
+        switch update := updates[index].(type) {
+        case Update:
+            if update.transaction != this.transaction.id {
+                panic("Update was not prepared during the current transaction.")
+            }
+
+            prepared[index] = update
+        case userupdate:
+            preparedupdate, error := this.Prepare(update)
+
+            if error != nil {
+                if this.AutoCollect.value {
+                    this.Collect()
+                }
+
+                return ChangeSet{}, BlockError{index, error}
+            }
+
+            prepared[index] = preparedupdate
+        default:
+            panic("ApplyBlock() only accepts Update objects or objects that implement the update interface.")
+        }
+    }
+
+    this.Begin()
+
+    committed := false
+    defer func() {
+        if !committed {
+            this.Rollback()
+
+            if this.AutoCollect.value {
+                this.Collect()
+            }
+        }
+    }()
+
+    for index := 0; index < len(prepared); index++ {
+        proxy := prepared[index].proxy
+        proxy.reset()
+
+        if !(prepared[index].update.Check(proxy)) {
+            return ChangeSet{}, BlockError{index, errors.New("Update check failed.")}
+        }
+
+        if proxy.failed() != nil {
+            return ChangeSet{}, BlockError{index, proxy.failed()}
+        }
+
+        prepared[index].update.Apply(proxy)
+
+        if proxy.failed() != nil {
+            return ChangeSet{}, BlockError{index, proxy.failed()}
+        }
+    }
+
+    committed = true
+    return this.End(), nil
+}
@@ -0,0 +1,144 @@
+package collection
+
+import "testing"
+
+func TestBlockApply(test *testing.T) {
+    ctx := testctx("[block.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    collection.Add([]byte("alice"), uint64(2))
+    collection.Add([]byte("bob"), uint64(0))
+    collection.Add([]byte("carol"), uint64(5))
+
+    alice, _ := collection.Get([]byte("alice")).Proof()
+    bob, _ := collection.Get([]byte("bob")).Proof()
+    carol, _ := collection.Get([]byte("carol")).Proof()
+
+    changeset, error := collection.ApplyBlock(TestUpdateDoubleRecordUpdate{alice, bob}, TestUpdateDoubleRecordUpdate{alice, bob}, TestUpdateSingleRecordUpdate{carol})
+
+    if error != nil {
+        test.Error("[block.go]", "[apply]", "ApplyBlock() yields an error on a valid block.")
+    }
+
+    if (len(changeset.Changes) != 3) || (changeset.NewRoot != collection.root.label) {
+        test.Error("[block.go]", "[apply]", "ApplyBlock() does not return the changes of the block.")
+    }
+
+    ctx.verify.values("[apply]", &collection, []byte("alice"), uint64(0))
+    ctx.verify.values("[apply]", &collection, []byte("bob"), uint64(2))
+    ctx.verify.values("[apply]", &collection, []byte("carol"), uint64(6))
+    ctx.verify.tree("[apply]", &collection)
+
+    if collection.transaction.ongoing {
+        test.Error("[block.go]", "[apply]", "ApplyBlock() leaves a transaction ongoing.")
+    }
+
+    bob, _ = collection.Get([]byte("bob")).Proof()
+    carol, _ = collection.Get([]byte("carol")).Proof()
+
+    transfer, _ := collection.Prepare(TestUpdateDoubleRecordUpdate{bob, carol})
+    updates := []interface{}{transfer, TestUpdateSingleRecordUpdate{bob}}
+
+    if _, error := collection.ApplyBlock(updates...); error != nil {
+        test.Error("[block.go]", "[prepared]", "ApplyBlock() yields an error on a block of prepared and user updates.")
+    }
+
+    ctx.verify.values("[prepared]", &collection, []byte("bob"), uint64(2))
+    ctx.verify.values("[prepared]", &collection, []byte("carol"), uint64(7))
+
+    ctx.should_panic("[prepared]", func() {
+        collection.ApplyBlock(transfer)
+    })
+
+    ctx.should_panic("[type]", func() {
+        collection.ApplyBlock(33)
+    })
+
+    if collection.transaction.ongoing {
+        test.Error("[block.go]", "[type]", "ApplyBlock() begins a transaction before checking the block.")
+    }
+}
+
+func TestBlockRollback(test *testing.T) {
+    ctx := testctx("[block.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    collection.Add([]byte("alice"), uint64(1))
+    collection.Add([]byte("bob"), uint64(0))
+
+    root := collection.root.label
+
+    alice, _ := collection.Get([]byte("alice")).Proof()
+    bob, _ := collection.Get([]byte("bob")).Proof()
+
+    _, error := collection.ApplyBlock(TestUpdateSingleRecordUpdate{bob}, TestUpdateDoubleRecordUpdate{alice, bob}, TestUpdateDoubleRecordUpdate{alice, bob})
+
+    blockerror, isblockerror := error.(BlockError)
+
+    if !isblockerror || (blockerror.Index != 2) || (blockerror.Cause == nil) {
+        test.Error("[block.go]", "[check]", "ApplyBlock() does not report the index of the failing update.")
+    }
+
+    if collection.root.label != root {
+        test.Error("[block.go]", "[check]", "ApplyBlock() does not undo the updates preceding a failure.")
+    }
+
+    ctx.verify.values("[check]", &collection, []byte("alice"), uint64(1))
+    ctx.verify.values("[check]", &collection, []byte("bob"), uint64(0))
+    ctx.verify.tree("[check]", &collection)
+
+    forged, _ := collection.Get([]byte("bob")).Proof()
+    forged.steps[0].Left.Label[0]++
+
+    _, error = collection.ApplyBlock(TestUpdateSingleRecordUpdate{alice}, TestUpdateSingleRecordUpdate{forged})
+    blockerror, isblockerror = error.(BlockError)
+
+    if !isblockerror || (blockerror.Index != 1) {
+        test.Error("[block.go]", "[proof]", "ApplyBlock() does not report the update with an invalid proof.")
+    }
+
+    if collection.root.label != root {
+        test.Error("[block.go]", "[proof]", "ApplyBlock() applies updates of a block with an invalid proof.")
+    }
+
+    _, error = collection.ApplyBlock(TestUpdateSingleRecordUpdate{alice}, TestBlockUndeclaredUpdate{bob})
+    blockerror, isblockerror = error.(BlockError)
+
+    if !isblockerror || (blockerror.Index != 1) {
+        test.Error("[block.go]", "[undeclared]", "ApplyBlock() does not report the update writing an undeclared key.")
+    }
+
+    if collection.transaction.ongoing || (collection.root.label != root) {
+        test.Error("[block.go]", "[undeclared]", "ApplyBlock() does not roll back when an update writes an undeclared key.")
+    }
+
+    ctx.verify.nokey("[undeclared]", &collection, []byte("undeclared"))
+
+    collection.Begin()
+
+    ctx.should_panic("[ongoing]", func() {
+        collection.ApplyBlock()
+    })
+
+    collection.End()
+}
+
+type TestBlockUndeclaredUpdate struct {
+    record Proof
+}
+
+func (this TestBlockUndeclaredUpdate) Records() []Proof {
+    return []Proof{this.record}
+}
+
+func (this TestBlockUndeclaredUpdate) Check(collection ReadOnly) bool {
+    return true
+}
+
+func (this TestBlockUndeclaredUpdate) Apply(collection ReadWrite) {
+    collection.Add([]byte("undeclared"), uint64(1))
+}
@@ -0,0 +1,181 @@
+package collection
+
+import "sort"
+import csha256 "crypto/sha256"
+
+// cache
+
+type cache struct {
+    nodes int
+    bytes int
+
+    clock uint64
+}
+
+// Getters
+
+func (this *cache) enabled() bool {
+    return (this.nodes > 0) || (this.bytes > 0)
+}
+
+// Methods
+
+func (this *cache) Nodes(limit int) {
+    if limit < 1 {
+        panic("Cache limit must be positive.")
+    }
+
+    this.nodes = limit
+    this.bytes = 0
+}
+
+func (this *cache) Bytes(limit int) {
+    if limit < 1 {
+        panic("Cache limit must be positive.")
+    }
+
+    this.nodes = 0
+    this.bytes = limit
+}
+
+func (this *cache) Disable() {
+    this.nodes = 0
+    this.bytes = 0
+}
+
+// Private methods
+
+func (this *cache) fits(nodes int, bytes int) bool {
+    return ((this.nodes == 0) || (nodes <= this.nodes)) && ((this.bytes == 0) || (bytes <= this.bytes))
+}
+
+// evictable
+
+type evictable struct {
+    node *node
+    depth int
+}
+
+// evictlist
+
+type evictlist []evictable
+
+// Interface
+
+func (this evictlist) Len() int {
+    return len(this)
+}
+
+func (this evictlist) Less(i int, j int) bool {
+    if this[i].node.access != this[j].node.access {
+        return this[i].node.access < this[j].node.access
+    }
+
+    return this[i].depth > this[j].depth
+}
+
+func (this evictlist) Swap(i int, j int) {
+    this[i], this[j] = this[j], this[i]
+}
+
+// collection
+
+// Private methods (collection) (cache)
+
+func (this *collection) touch(node *node) {
+    if !(this.Cache.enabled()) {
+        return
+    }
+
+    this.Cache.clock++
+
+    for cursor := node; cursor != nil; cursor = cursor.parent {
+        cursor.access = this.Cache.clock
+    }
+}
+
+func (this *collection) evict() {
+    if !(this.root.known) {
+        return
+    }
+
+    candidates := evictlist{}
+
+    nodes := 0
+    bytes := 0
+
+    var explore func(*node, [csha256.Size]byte, int, bool) bool
+    explore = func(node *node, path [csha256.Size]byte, bit int, out bool) bool {
+        if !(node.known) {
+            return false
+        }
+
+        nodes++
+        bytes += node.size()
+
+        touched := node.transaction.inconsistent || (node.transaction.backup != nil)
+
+        if !(node.leaf()) {
+            childout := out || ((bit >= 0) && !(this.keep(path, bit)))
+
+            setbit(path[:], bit + 1, false)
+            lefttouched := explore(node.children.left, path, bit + 1, childout)
+
+            setbit(path[:], bit + 1, true)
+            righttouched := explore(node.children.right, path, bit + 1, childout)
+
+            touched = touched || lefttouched || righttouched
+        }
+
+        if out && !touched {
+            candidates = append(candidates, evictable{node, bit + 1})
+        }
+
+        return touched
+    }
+
+    var path [csha256.Size]byte
+    none := true
+
+    setbit(path[:], 0, false)
+    if this.keep(path, 0) {
+        none = false
+    }
+
+    setbit(path[:], 0, true)
+    if this.keep(path, 0) {
+        none = false
+    }
+
+    explore(this.root, path, -1, none)
+
+    sort.Sort(candidates)
+
+    for _, candidate := range(candidates) {
+        if this.Cache.fits(nodes, bytes) {
+            break
+        }
+
+        var measure func(*node)
+        measure = func(node *node) {
+            if node.known {
+                nodes--
+                bytes -= node.size()
+
+                if !(node.leaf()) {
+                    measure(node.children.left)
+                    measure(node.children.right)
+                }
+            }
+        }
+
+        measure(candidate.node)
+        this.indexforget(candidate.node)
+
+        candidate.node.known = false
+        candidate.node.key = []byte{}
+        candidate.node.values = [][]byte{}
+
+        candidate.node.prune()
+    }
+}
@@ -0,0 +1,196 @@
+package collection
+
+import "testing"
+
+func TestCacheMethods(test *testing.T) {
+    ctx := testctx("[cache.go]", test)
+
+    cache := cache{}
+
+    if cache.enabled() {
+        test.Error("[cache.go]", "[enabled]", "Zero-valued cache is enabled.")
+    }
+
+    cache.Nodes(12)
+
+    if !(cache.enabled()) || !(cache.fits(12, 1000)) || cache.fits(13, 0) {
+        test.Error("[cache.go]", "[nodes]", "Nodes() does not set a limit on the number of nodes.")
+    }
+
+    cache.Bytes(100)
+
+    if !(cache.fits(1000, 100)) || cache.fits(0, 101) {
+        test.Error("[cache.go]", "[bytes]", "Bytes() does not replace the limit with a limit on bytes.")
+    }
+
+    cache.Disable()
+
+    if cache.enabled() {
+        test.Error("[cache.go]", "[disable]", "Disable() does not disable the cache.")
+    }
+
+    ctx.should_panic("[nodes]", func() {
+        cache.Nodes(0)
+    })
+
+    ctx.should_panic("[bytes]", func() {
+        cache.Bytes(-1)
+    })
+}
+
+func TestCacheTouch(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 16; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    clock := collection.Cache.clock
+
+    collection.Get([]byte{3}).Record()
+    proof, _ := collection.Get([]byte{5}).Proof()
+    collection.Verify(proof)
+
+    if (collection.Cache.clock != clock) || (collection.root.access != 0) {
+        test.Error("[cache.go]", "[touch]", "Reads record accesses while the cache is disabled.")
+    }
+
+    collection.Cache.Nodes(1)
+    collection.Get([]byte{3}).Record()
+
+    if (collection.Cache.clock == clock) || (collection.root.access != collection.Cache.clock) {
+        test.Error("[cache.go]", "[touch]", "Reads do not record accesses while the cache is enabled.")
+    }
+}
+
+func TestCacheEviction(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 64; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    known := func(root *node) int {
+        count := 0
+
+        var explore func(*node)
+        explore = func(node *node) {
+            if node.known {
+                count++
+
+                if !(node.leaf()) {
+                    explore(node.children.left)
+                    explore(node.children.right)
+                }
+            }
+        }
+
+        explore(root)
+        return count
+    }
+
+    verifier := EmptyVerifier(stake64)
+    verifier.root.label = collection.root.label
+    verifier.Cache.Nodes(32)
+
+    for index := 0; index < 64; index++ {
+        proof, _ := collection.Get([]byte{byte(index)}).Proof()
+
+        if !(verifier.Verify(proof)) {
+            test.Error("[cache.go]", "[verify]", "Verify() rejects a valid proof with the cache enabled.")
+        }
+
+        if known(verifier.root) > 32 {
+            test.Error("[cache.go]", "[verify]", "Verify() exceeds the cache budget.")
+            break
+        }
+    }
+
+    if _, error := verifier.Get([]byte{63}).Record(); error != nil {
+        test.Error("[cache.go]", "[verify]", "Verify() evicts the most recently verified record.")
+    }
+
+    if _, error := verifier.Get([]byte{0}).Record(); error == nil {
+        test.Error("[cache.go]", "[verify]", "Verify() keeps the least recently verified record.")
+    }
+
+    fetcher := EmptyVerifier(stake64)
+    fetcher.root.label = collection.root.label
+    fetcher.Source = CollectionSource(&collection)
+    fetcher.Cache.Nodes(32)
+
+    for index := 0; index < 64; index++ {
+        if _, error := fetcher.Get([]byte{byte(index)}).Record(); error != nil {
+            test.Error("[cache.go]", "[source]", "Get() fails to fetch a record with the cache enabled.")
+        }
+
+        if known(fetcher.root) > 32 {
+            test.Error("[cache.go]", "[source]", "Get() exceeds the cache budget when fetching from a source.")
+            break
+        }
+    }
+
+    total := known(collection.root)
+
+    collection.Cache.Nodes(total)
+    collection.Scope.None()
+    collection.Scope.AddKey([]byte{3})
+    collection.Collect()
+
+    if _, error := collection.Get([]byte{3}).Record(); error != nil {
+        test.Error("[cache.go]", "[collect]", "Collect() evicts a record in scope.")
+    }
+
+    if _, error := collection.Get([]byte{5}).Record(); error == nil {
+        test.Error("[cache.go]", "[collect]", "Collect() keeps an out-of-scope record that fits the budget.")
+    }
+
+    collection.Cache.Nodes(1)
+    collection.Collect()
+
+    if _, error := collection.Get([]byte{3}).Record(); error != nil {
+        test.Error("[cache.go]", "[collect]", "Collect() evicts a record in scope on an unreachable budget.")
+    }
+}
+
+func TestCacheTransaction(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 16; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    collection.Cache.Bytes(1)
+    collection.Scope.None()
+
+    collection.Begin()
+    collection.Set([]byte{7}, uint64(70))
+    collection.Collect()
+
+    if _, error := collection.Get([]byte{7}).Record(); error != nil {
+        test.Error("[cache.go]", "[transaction]", "Collect() evicts a record touched by the ongoing transaction.")
+    }
+
+    if _, error := collection.Get([]byte{8}).Record(); error == nil {
+        test.Error("[cache.go]", "[transaction]", "Collect() does not evict records untouched by the ongoing transaction.")
+    }
+
+    collection.Rollback()
+
+    record, error := collection.Get([]byte{7}).Record()
+
+    if error != nil {
+        test.Error("[cache.go]", "[transaction]", "Rollback() fails to restore a record kept by the cache.")
+    } else if values, _ := record.Values(); values[0].(uint64) != 7 {
+        test.Error("[cache.go]", "[transaction]", "Rollback() does not restore the values of a record kept by the cache.")
+    }
+
+    collection.Collect()
+
+    if collection.root.known {
+        test.Error("[cache.go]", "[transaction]", "Collect() does not evict an out-of-scope root after the transaction.")
+    }
+}
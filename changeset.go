@@ -0,0 +1,162 @@
+package collection
+
+import "errors"
+import csha256 "crypto/sha256"
+
+// Change
+
+type Change struct {
+    Key []byte
+    Old [][]byte
+    New [][]byte
+}
+
+// Getters
+
+func (this Change) Added() bool {
+    return (this.Old == nil) && (this.New != nil)
+}
+
+func (this Change) Removed() bool {
+    return (this.Old != nil) && (this.New == nil)
+}
+
+// ChangeSet
+
+type ChangeSet struct {
+    Changes []Change
+
+    OldRoot [csha256.Size]byte
+    NewRoot [csha256.Size]byte
+}
+
+// collection
+
+// Private methods (collection) (changeset)
+
+func (this *collection) changes() []Change {
+    before := make(map[string][][]byte)
+    beforekeys := [][]byte{}
+
+    after := make(map[string][][]byte)
+    afterkeys := [][]byte{}
+
+    var explore func(*node, bool)
+    explore = func(node *node, committed bool) {
+        touched := node.transaction.inconsistent || (node.transaction.backup != nil)
+        view := node
+
+        if committed {
+            view = node.committed()
+        }
+
+        if !(view.known) {
+            return
+        }
+
+        if view.leaf() {
+            if !(view.placeholder()) {
+                values := make([][]byte, len(view.values))
+                copy(values, view.values)
+
+                if committed {
+                    before[string(view.key)] = values
+                    beforekeys = append(beforekeys, view.key)
+                } else {
+                    after[string(view.key)] = values
+                    afterkeys = append(afterkeys, view.key)
+                }
+            }
+        } else if touched {
+            explore(view.children.left, committed)
+            explore(view.children.right, committed)
+        }
+    }
+
+    explore(this.root, true)
+    explore(this.root, false)
+
+    changes := []Change{}
+
+    for _, key := range(afterkeys) {
+        previous, existed := before[string(key)]
+        current := after[string(key)]
+
+        if existed && (len(previous) == len(current)) {
+            same := true
+
+            for index := 0; index < len(previous); index++ {
+                if !(equal(previous[index], current[index])) {
+                    same = false
+                    break
+                }
+            }
+
+            if same {
+                continue
+            }
+        }
+
+        changes = append(changes, Change{key, previous, current})
+    }
+
+    for _, key := range(beforekeys) {
+        if _, exists := after[string(key)]; !exists {
+            changes = append(changes, Change{key, before[string(key)], nil})
+        }
+    }
+
+    return changes
+}
+
+func (this *collection) applychanges(changes []Change, root [csha256.Size]byte) error {
+    this.Begin()
+
+    for _, change := range(changes) {
+        record, error := this.Get(change.Key).Record()
+
+        if error == nil {
+            if change.New == nil {
+                if record.Match() {
+                    error = this.Remove(change.Key)
+                } else {
+                    error = errors.New("Removing a missing key.")
+                }
+            } else {
+                values := make([][]byte, len(change.New))
+                copy(values, change.New)
+
+                if record.Match() {
+                    error = this.set(change.Key, values)
+                } else {
+                    error = this.add(change.Key, values)
+                }
+            }
+        }
+
+        if error != nil {
+            this.Rollback()
+
+            if this.AutoCollect.value {
+                this.Collect()
+            }
+
+            return error
+        }
+    }
+
+    this.fix()
+
+    if this.root.label != root {
+        this.Rollback()
+
+        if this.AutoCollect.value {
+            this.Collect()
+        }
+
+        return errors.New("Changes yield a wrong root.")
+    }
+
+    this.End()
+    return nil
+}
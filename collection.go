@@ -1,16 +1,33 @@
 package collection
 
+import csha256 "crypto/sha256"
+
 // Structs
 
 type collection struct {
     root *node
     fields []Field
-    Scope scope
+    Scope Scope
 
     AutoCollect flag
+    Strict flag
+    Cache cache
+    Source ProofSource
+    Journal *Journal
+    pins map[[csha256.Size]byte]int
+    salt []byte
+
+    Limits limits
+    indexes map[int]*index
+
     transaction struct {
         ongoing bool
         id uint64
+        savepoints int
+
+        handles int
+        clock uint64
+        versions map[[csha256.Size]byte]uint64
     }
 }
 
@@ -63,6 +80,16 @@ func (this *collection) Clone() (collection collection) {
 
     collection.Scope = this.Scope.clone()
     collection.AutoCollect = this.AutoCollect
+    collection.Strict = this.Strict
+    collection.Cache = this.Cache
+    collection.Source = this.Source
+    collection.salt = this.salt
+    collection.Limits = this.Limits
+
+    collection.pins = make(map[[csha256.Size]byte]int)
+    for path, count := range(this.pins) {
+        collection.pins[path] = count
+    }
 
     collection.transaction.ongoing = false
     collection.transaction.id = 0
@@ -71,6 +98,7 @@ func (this *collection) Clone() (collection collection) {
     explore = func(dstcursor *node, srccursor *node) {
         dstcursor.label = srccursor.label
         dstcursor.known = srccursor.known
+        dstcursor.access = srccursor.access
 
         dstcursor.transaction.inconsistent = false
         dstcursor.transaction.backup = nil
@@ -88,5 +116,44 @@ func (this *collection) Clone() (collection collection) {
 
     explore(collection.root, this.root)
 
+    for field, _ := range(this.indexes) {
+        collection.Index(field)
+    }
+
     return
 }
+
+func (this *collection) Salt(salt []byte) {
+    if this.transaction.ongoing {
+        panic("Cannot salt a collection while a transaction is ongoing.")
+    }
+
+    if len(this.pins) > 0 {
+        panic("Cannot salt a collection with pinned keys.")
+    }
+
+    empty := EmptyCollection(this.fields...)
+
+    if this.root.label != empty.root.label {
+        panic("Cannot salt a non-empty collection.")
+    }
+
+    this.salt = make([]byte, len(salt))
+    copy(this.salt, salt)
+
+    this.Scope.salted(salt)
+}
+
+func (this *collection) InScope(key []byte) bool {
+    return this.Scope.saltedmatch(this.path(key), 8 * csha256.Size, this.salt)
+}
+
+// Private methods
+
+func (this *collection) path(key []byte) [csha256.Size]byte {
+    if len(this.salt) == 0 {
+        return sha256(key)
+    }
+
+    return sha256(this.salt, key)
+}
@@ -166,3 +166,112 @@ func TestCollectionClone(test *testing.T) {
         collection.End()
     })
 }
+
+func TestCollectionSalt(test *testing.T) {
+    ctx := testctx("[collection.go]", test)
+
+    stake64 := Stake64{}
+
+    plain := EmptyCollection(stake64)
+    salted := EmptyCollection(stake64)
+    salted.Salt([]byte("mysalt"))
+
+    for index := 0; index < 64; index++ {
+        plain.Add([]byte{byte(index)}, uint64(index))
+        salted.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    ctx.verify.tree("[salt]", &salted)
+
+    for index := 0; index < 64; index++ {
+        ctx.verify.values("[salt]", &salted, []byte{byte(index)}, uint64(index))
+    }
+
+    if plain.root.label == salted.root.label {
+        test.Error("[collection.go]", "[salt]", "Salt() does not change the path derivation.")
+    }
+
+    verifier := EmptyVerifier(stake64)
+    verifier.Salt([]byte("mysalt"))
+    verifier.root.label = salted.root.label
+
+    proof, _ := salted.Get([]byte{3}).Proof()
+
+    if !(verifier.Verify(proof)) || !(proof.Match()) {
+        test.Error("[collection.go]", "[salt]", "Salted verifier rejects a proof from a collection with the same salt.")
+    }
+
+    clone := salted.Clone()
+    clone.Add([]byte{64}, uint64(64))
+    salted.Add([]byte{64}, uint64(64))
+
+    if clone.root.label != salted.root.label {
+        test.Error("[collection.go]", "[salt]", "Clone() does not copy the salt.")
+    }
+
+    scoped := EmptyCollection(stake64)
+    scoped.Scope.None()
+    scoped.Scope.AddKey([]byte{3})
+    scoped.Salt([]byte("mysalt"))
+
+    for index := 0; index < 8; index++ {
+        scoped.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    if _, error := scoped.Get([]byte{3}).Record(); error != nil {
+        test.Error("[collection.go]", "[salt]", "Salt() does not rederive the paths of the keys in scope.")
+    }
+
+    assigned := EmptyCollection(stake64)
+    assigned.Salt([]byte("mysalt"))
+
+    for index := 0; index < 64; index++ {
+        assigned.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    scope := Scope{}
+    scope.None()
+    scope.AddKey([]byte{5})
+
+    assigned.Scope = scope
+    assigned.Collect()
+
+    if _, error := assigned.Get([]byte{5}).Record(); error != nil {
+        test.Error("[collection.go]", "[salt]", "Assigning a scope to a salted collection does not salt the paths of its keys.")
+    }
+
+    known := 0
+
+    for index := 0; index < 64; index++ {
+        if _, error := assigned.Get([]byte{byte(index)}).Record(); error == nil {
+            known++
+        }
+    }
+
+    if known == 64 {
+        test.Error("[collection.go]", "[salt]", "Assigning a scope to a salted collection does not drop the keys out of scope.")
+    }
+
+    restored := EmptyCollection(stake64)
+
+    if restored.Load(salted.Save()) != nil {
+        test.Error("[collection.go]", "[salt]", "Load() fails on a snapshot of a salted collection.")
+    }
+
+    restored.Add([]byte{65}, uint64(65))
+    salted.Add([]byte{65}, uint64(65))
+
+    if restored.root.label != salted.root.label {
+        test.Error("[collection.go]", "[salt]", "Load() does not restore the salt.")
+    }
+
+    ctx.should_panic("[salt]", func() {
+        salted.Salt([]byte("myothersalt"))
+    })
+
+    ctx.should_panic("[salt]", func() {
+        pinned := EmptyCollection(stake64)
+        pinned.Pin([]byte{3})
+        pinned.Salt([]byte("mysalt"))
+    })
+}
@@ -0,0 +1,218 @@
+package collection
+
+import "errors"
+import csha256 "crypto/sha256"
+import "github.com/dedis/protobuf"
+
+// deltachange
+
+type deltachange struct {
+    Key []byte
+    Old [][]byte
+    New [][]byte
+    Added bool
+    Removed bool
+}
+
+// Delta
+
+type Delta struct {
+    proofs []Proof
+    changes []Change
+    root [csha256.Size]byte
+}
+
+// Getters
+
+func (this Delta) Root() [csha256.Size]byte {
+    return this.root
+}
+
+func (this Delta) Records() []Proof {
+    return this.proofs
+}
+
+func (this Delta) Keys() [][]byte {
+    keys := make([][]byte, len(this.changes))
+
+    for index := 0; index < len(this.changes); index++ {
+        keys[index] = this.changes[index].Key
+    }
+
+    return keys
+}
+
+// Private functions
+
+func deltavalues(values [][]byte) [][]byte {
+    copied := [][]byte{}
+
+    for _, value := range(values) {
+        copied = append(copied, append([]byte{}, value...))
+    }
+
+    return copied
+}
+
+// dump
+
+// Constructors
+
+func dumpcommitted(node *node) (dump dump) {
+    committed := node.committed()
+
+    dump.Label = committed.label
+    dump.Values = make([][]byte, len(committed.values))
+    copy(dump.Values, committed.values)
+
+    if committed.leaf() {
+        dump.Key = committed.key
+    } else {
+        dump.Children.Left = committed.children.left.committed().label
+        dump.Children.Right = committed.children.right.committed().label
+    }
+
+    return
+}
+
+// collection
+
+// Methods (collection) (delta)
+
+func (this *collection) Delta() (Delta, error) {
+    if !(this.transaction.ongoing) {
+        panic("Delta() called outside of a transaction.")
+    }
+
+    this.fix()
+
+    delta := Delta{[]Proof{}, this.changes(), this.root.label}
+
+    for _, change := range(delta.changes) {
+        proof, error := this.committedproof(change.Key)
+
+        if error != nil {
+            return Delta{}, error
+        }
+
+        delta.proofs = append(delta.proofs, proof)
+    }
+
+    return delta, nil
+}
+
+func (this *collection) SerializeDelta(delta Delta) []byte {
+    serializable := struct {
+        Root [csha256.Size]byte
+        Proofs [][]byte
+        Changes []deltachange
+    }{delta.root, [][]byte{}, []deltachange{}}
+
+    for _, proof := range(delta.proofs) {
+        serializable.Proofs = append(serializable.Proofs, this.Serialize(proof))
+    }
+
+    for _, change := range(delta.changes) {
+        serializable.Changes = append(serializable.Changes, deltachange{change.Key, change.Old, change.New, change.Old == nil, change.New == nil})
+    }
+
+    buffer, _ := protobuf.Encode(&serializable)
+    return buffer
+}
+
+func (this *collection) DeserializeDelta(buffer []byte) (Delta, error) {
+    deserializable := struct {
+        Root [csha256.Size]byte
+        Proofs [][]byte
+        Changes []deltachange
+    }{}
+
+    error := protobuf.Decode(buffer, &deserializable)
+
+    if error != nil {
+        return Delta{}, error
+    }
+
+    if len(deserializable.Proofs) != len(deserializable.Changes) {
+        return Delta{}, errors.New("Invalid delta: proofs do not match changes.")
+    }
+
+    delta := Delta{make([]Proof, len(deserializable.Proofs)), make([]Change, len(deserializable.Changes)), deserializable.Root}
+
+    for index := 0; index < len(deserializable.Proofs); index++ {
+        delta.proofs[index], error = this.Deserialize(deserializable.Proofs[index])
+
+        if error != nil {
+            return Delta{}, error
+        }
+
+        change := deserializable.Changes[index]
+
+        if !(equal(delta.proofs[index].key, change.Key)) {
+            return Delta{}, errors.New("Invalid delta: proofs do not match changes.")
+        }
+
+        delta.changes[index].Key = append([]byte{}, change.Key...)
+
+        if !(change.Added) {
+            delta.changes[index].Old = deltavalues(change.Old)
+        }
+
+        if !(change.Removed) {
+            delta.changes[index].New = deltavalues(change.New)
+        }
+    }
+
+    return delta, nil
+}
+
+// Private methods (collection) (delta)
+
+func (this *collection) committedproof(key []byte) (Proof, error) {
+    var proof Proof
+
+    proof.collection = this
+    proof.key = key
+
+    cursor := this.root.committed()
+
+    if !(cursor.known) {
+        return proof, errors.New("Record lies in unknown subtree.")
+    }
+
+    proof.root = dumpcommitted(this.root)
+    path := this.path(key)
+
+    for depth := 0; !(cursor.leaf()); depth++ {
+        left := cursor.children.left.committed()
+        right := cursor.children.right.committed()
+
+        if !(left.known) || !(right.known) {
+            return proof, errors.New("Record lies in unknown subtree.")
+        }
+
+        proof.steps = append(proof.steps, step{dumpcommitted(cursor.children.left), dumpcommitted(cursor.children.right)})
+
+        if bit(path[:], depth) {
+            cursor = right
+        } else {
+            cursor = left
+        }
+    }
+
+    return proof, nil
+}
+
+func (this *collection) applydelta(delta Delta) error {
+    if this.transaction.ongoing {
+        panic("Cannot apply a delta while a transaction is ongoing.")
+    }
+
+    for index := 0; index < len(delta.proofs); index++ {
+        if !(this.Verify(delta.proofs[index])) {
+            return errors.New("Invalid delta: proof invalid.")
+        }
+    }
+
+    return this.applychanges(delta.changes, delta.root)
+}
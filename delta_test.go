@@ -0,0 +1,223 @@
+package collection
+
+import "testing"
+
+func TestDeltaGenerate(test *testing.T) {
+    ctx := testctx("[delta.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 64; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    before := collection.root.label
+
+    collection.Begin()
+
+    collection.Set([]byte{3}, uint64(30))
+    collection.Set([]byte{5}, uint64(5))
+    collection.Add([]byte{100}, uint64(100))
+    collection.Remove([]byte{7})
+
+    delta, error := collection.Delta()
+
+    if error != nil {
+        test.Error("[delta.go]", "[delta]", "Delta() yields an error on a known collection.")
+    }
+
+    keys := delta.Keys()
+
+    if len(keys) != 3 {
+        test.Error("[delta.go]", "[delta]", "Delta() does not list exactly the keys changed by the transaction.")
+    }
+
+    for _, key := range(keys) {
+        if !(equal(key, []byte{3}) || equal(key, []byte{100}) || equal(key, []byte{7})) {
+            test.Error("[delta.go]", "[delta]", "Delta() lists a key that was not changed.")
+        }
+    }
+
+    if len(delta.Records()) != len(keys) {
+        test.Error("[delta.go]", "[delta]", "Delta() does not provide a proof for every change.")
+    }
+
+    for _, proof := range(delta.Records()) {
+        if (proof.root.Label != before) || !(proof.consistent()) {
+            test.Error("[delta.go]", "[delta]", "Delta() provides proofs that are not valid against the old root.")
+        }
+    }
+
+    if delta.Root() != collection.root.label {
+        test.Error("[delta.go]", "[delta]", "Delta() does not provide the new root.")
+    }
+
+    collection.Rollback()
+
+    if collection.root.label != before {
+        test.Error("[delta.go]", "[rollback]", "Rollback() does not restore the root after Delta().")
+    }
+
+    ctx.verify.tree("[rollback]", &collection)
+    ctx.verify.values("[rollback]", &collection, []byte{3}, uint64(3))
+    ctx.verify.nokey("[rollback]", &collection, []byte{100})
+
+    ctx.should_panic("[delta]", func() {
+        collection.Delta()
+    })
+}
+
+func TestDeltaApply(test *testing.T) {
+    ctx := testctx("[delta.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 64; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    verifier := EmptyVerifier(stake64)
+    verifier.root.label = collection.root.label
+
+    stale := EmptyVerifier(stake64)
+    stale.root.label = collection.root.label
+
+    collection.Begin()
+
+    collection.Set([]byte{3}, uint64(30))
+    collection.Add([]byte{100}, uint64(100))
+    collection.Remove([]byte{7})
+    collection.Remove([]byte{8})
+
+    delta, _ := collection.Delta()
+    collection.End()
+
+    if verifier.Apply(delta) != nil {
+        test.Error("[delta.go]", "[apply]", "Apply() rejects a valid delta.")
+    }
+
+    if verifier.root.label != collection.root.label {
+        test.Error("[delta.go]", "[apply]", "Apply() does not move the verifier to the new root.")
+    }
+
+    if verifier.root.known {
+        test.Error("[delta.go]", "[apply]", "Apply() does not collect the nodes learnt from a delta.")
+    }
+
+    if verifier.Apply(delta) == nil {
+        test.Error("[delta.go]", "[apply]", "Apply() accepts a delta generated against a different root.")
+    }
+
+    forged := delta
+    forged.root[0] ^= 1
+
+    if stale.Apply(forged) == nil {
+        test.Error("[delta.go]", "[apply]", "Apply() accepts a delta with a wrong root.")
+    }
+
+    forged = delta
+    forged.changes = append([]Change{}, delta.changes...)
+    forged.changes[0].New = [][]byte{stake64.Encode(uint64(31))}
+
+    if stale.Apply(forged) == nil {
+        test.Error("[delta.go]", "[apply]", "Apply() accepts a delta with forged values.")
+    }
+
+    if stale.root.label == collection.root.label || stale.root.known {
+        test.Error("[delta.go]", "[apply]", "Apply() alters the verifier on a rejected delta.")
+    }
+
+    stale.Scope.Add([]byte{}, 0)
+
+    if stale.Apply(delta) != nil {
+        test.Error("[delta.go]", "[apply]", "Apply() rejects a valid delta after rejecting forged ones.")
+    }
+
+    ctx.verify.values("[apply]", &stale, []byte{3}, uint64(30))
+    ctx.verify.values("[apply]", &stale, []byte{100}, uint64(100))
+    ctx.verify.nokey("[apply]", &stale, []byte{7})
+
+    stale.Begin()
+
+    ctx.should_panic("[apply]", func() {
+        stale.Apply(delta)
+    })
+
+    stale.End()
+}
+
+func TestDeltaSerialization(test *testing.T) {
+    ctx := testctx("[delta.go]", test)
+
+    stake64 := Stake64{}
+    data := Data{}
+
+    collection := EmptyCollection(stake64, data)
+
+    for index := 0; index < 64; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index), []byte{byte(index)})
+    }
+
+    verifier := EmptyVerifier(stake64, data)
+    verifier.root.label = collection.root.label
+    verifier.Scope.Add([]byte{}, 0)
+
+    collection.Begin()
+
+    collection.Set([]byte{3}, uint64(30), []byte{})
+    collection.Add([]byte{100}, uint64(100), []byte("hundred"))
+    collection.Remove([]byte{7})
+
+    delta, _ := collection.Delta()
+    collection.End()
+
+    buffer := collection.SerializeDelta(delta)
+    received, error := verifier.DeserializeDelta(buffer)
+
+    if error != nil {
+        test.Error("[delta.go]", "[serialize]", "DeserializeDelta() yields an error on a valid delta.")
+    }
+
+    if received.Root() != delta.Root() {
+        test.Error("[delta.go]", "[serialize]", "DeserializeDelta() does not preserve the root.")
+    }
+
+    if len(received.Keys()) != len(delta.Keys()) || len(received.Records()) != len(delta.Records()) {
+        test.Error("[delta.go]", "[serialize]", "DeserializeDelta() does not preserve the changes and proofs.")
+    }
+
+    for index := 0; index < len(delta.changes); index++ {
+        if (received.changes[index].Old == nil) != (delta.changes[index].Old == nil) || (received.changes[index].New == nil) != (delta.changes[index].New == nil) {
+            test.Error("[delta.go]", "[serialize]", "DeserializeDelta() does not distinguish added and removed keys.")
+        }
+    }
+
+    if verifier.Apply(received) != nil {
+        test.Error("[delta.go]", "[serialize]", "Apply() rejects a deserialized delta.")
+    }
+
+    if verifier.root.label != collection.root.label {
+        test.Error("[delta.go]", "[serialize]", "Apply() does not move the verifier to the new root after deserialization.")
+    }
+
+    ctx.verify.values("[serialize]", &verifier, []byte{3}, uint64(30), []byte{})
+    ctx.verify.values("[serialize]", &verifier, []byte{100}, uint64(100), []byte("hundred"))
+    ctx.verify.nokey("[serialize]", &verifier, []byte{7})
+
+    if _, error := verifier.DeserializeDelta(buffer[:len(buffer) / 2]); error == nil {
+        test.Error("[delta.go]", "[serialize]", "DeserializeDelta() does not yield an error on a truncated buffer.")
+    }
+
+    if _, error := verifier.DeserializeDelta([]byte("definitely not a delta")); error == nil {
+        test.Error("[delta.go]", "[serialize]", "DeserializeDelta() does not yield an error on a garbage buffer.")
+    }
+
+    mismatched := delta
+    mismatched.proofs = delta.proofs[1:]
+
+    if _, error := verifier.DeserializeDelta(collection.SerializeDelta(mismatched)); error == nil {
+        test.Error("[delta.go]", "[serialize]", "DeserializeDelta() accepts proofs that do not match the changes.")
+    }
+}
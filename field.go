@@ -22,6 +22,20 @@ type Field interface {
     Navigate([]byte, []byte, []byte, []byte) (Navigation, error)
 }
 
+type Validator interface {
+    Validate([]byte) error
+}
+
+type Searchable interface {
+    Field
+    Match([]byte, []byte) bool
+}
+
+type Ordered interface {
+    Field
+    Before([]byte, []byte) (bool, error)
+}
+
 // Structs
 
 // Data
@@ -75,6 +89,14 @@ func (this Stake64) Decode(raw []byte) (interface{}, error) {
     }
 }
 
+func (this Stake64) Validate(raw []byte) error {
+    if len(raw) != 8 {
+        return errors.New("Wrong buffer length.")
+    } else {
+        return nil
+    }
+}
+
 func (this Stake64) Placeholder() []byte {
     return this.Encode(uint64(0))
 }
@@ -125,3 +147,287 @@ func (this Stake64) Navigate(query []byte, parent []byte, left []byte, right []b
         return Left, nil
     }
 }
+
+// Bloom
+
+type Bloom struct {
+}
+
+const(
+    bloomsize = 32
+    bloomhashes = 4
+)
+
+// Interface
+
+func (this Bloom) Encode(generic interface{}) []byte {
+    var tags [][]byte
+
+    switch value := generic.(type) {
+    case []byte:
+        tags = [][]byte{value}
+    case [][]byte:
+        tags = value
+    default:
+        panic("Bloom values must be a tag or a slice of tags.")
+    }
+
+    raw := make([]byte, bloomsize)
+
+    for _, tag := range(tags) {
+        hash := sha256(tag)
+
+        for index := 0; index < bloomhashes; index++ {
+            position := binary.BigEndian.Uint16(hash[2 * index:]) % (8 * bloomsize)
+            setbit(raw, int(position), true)
+        }
+    }
+
+    return raw
+}
+
+func (this Bloom) Decode(raw []byte) (interface{}, error) {
+    if len(raw) != bloomsize {
+        return []byte{}, errors.New("Wrong buffer length.")
+    } else {
+        return raw, nil
+    }
+}
+
+func (this Bloom) Validate(raw []byte) error {
+    if len(raw) != bloomsize {
+        return errors.New("Wrong buffer length.")
+    } else {
+        return nil
+    }
+}
+
+func (this Bloom) Placeholder() []byte {
+    return make([]byte, bloomsize)
+}
+
+func (this Bloom) Parent(left []byte, right []byte) ([]byte, error) {
+    if (len(left) != bloomsize) || (len(right) != bloomsize) {
+        return []byte{}, errors.New("Wrong buffer length.")
+    }
+
+    parent := make([]byte, bloomsize)
+
+    for index := 0; index < bloomsize; index++ {
+        parent[index] = left[index] | right[index]
+    }
+
+    return parent, nil
+}
+
+func (this Bloom) Navigate(query []byte, parent []byte, left []byte, right []byte) (Navigation, error) {
+    return false, errors.New("Bloom values cannot be navigated, only searched.")
+}
+
+func (this Bloom) Match(query []byte, raw []byte) bool {
+    if (len(query) != bloomsize) || (len(raw) != bloomsize) {
+        return false
+    }
+
+    return this.contains(raw, query)
+}
+
+// Methods
+
+func (this Bloom) Test(raw []byte, tag []byte) bool {
+    if len(raw) != bloomsize {
+        return false
+    }
+
+    return this.contains(raw, this.Encode(tag))
+}
+
+// Private methods
+
+func (this Bloom) contains(filter []byte, query []byte) bool {
+    for index := 0; index < bloomsize; index++ {
+        if (filter[index] & query[index]) != query[index] {
+            return false
+        }
+    }
+
+    return true
+}
+
+// Max64
+
+type Max64 struct {
+}
+
+// Interface
+
+func (this Max64) Encode(generic interface{}) []byte {
+    value := generic.(uint64)
+    raw := make([]byte, 8)
+
+    binary.BigEndian.PutUint64(raw, value)
+    return raw
+}
+
+func (this Max64) Decode(raw []byte) (interface{}, error) {
+    if len(raw) != 8 {
+        return 0, errors.New("Wrong buffer length.")
+    } else {
+        return binary.BigEndian.Uint64(raw), nil
+    }
+}
+
+func (this Max64) Validate(raw []byte) error {
+    if len(raw) != 8 {
+        return errors.New("Wrong buffer length.")
+    } else {
+        return nil
+    }
+}
+
+func (this Max64) Placeholder() []byte {
+    return this.Encode(uint64(0))
+}
+
+func (this Max64) Parent(left []byte, right []byte) ([]byte, error) {
+    before, error := this.Before(right, left)
+
+    if error != nil {
+        return []byte{}, error
+    }
+
+    if before {
+        return right, nil
+    } else {
+        return left, nil
+    }
+}
+
+func (this Max64) Navigate(query []byte, parent []byte, left []byte, right []byte) (Navigation, error) {
+    parentbefore, parenterror := this.Before(query, parent)
+
+    if parenterror != nil {
+        return false, parenterror
+    }
+
+    if parentbefore {
+        return false, errors.New("Query exceeds parent maximum.")
+    }
+
+    leftbefore, lefterror := this.Before(query, left)
+
+    if lefterror != nil {
+        return false, lefterror
+    }
+
+    if leftbefore {
+        return Right, nil
+    } else {
+        return Left, nil
+    }
+}
+
+func (this Max64) Before(lho []byte, rho []byte) (bool, error) {
+    lhovalue, lhoerror := this.Decode(lho)
+
+    if lhoerror != nil {
+        return false, lhoerror
+    }
+
+    rhovalue, rhoerror := this.Decode(rho)
+
+    if rhoerror != nil {
+        return false, rhoerror
+    }
+
+    return lhovalue.(uint64) > rhovalue.(uint64), nil
+}
+
+// Min64
+
+type Min64 struct {
+}
+
+// Interface
+
+func (this Min64) Encode(generic interface{}) []byte {
+    value := generic.(uint64)
+    raw := make([]byte, 8)
+
+    binary.BigEndian.PutUint64(raw, value)
+    return raw
+}
+
+func (this Min64) Decode(raw []byte) (interface{}, error) {
+    if len(raw) != 8 {
+        return 0, errors.New("Wrong buffer length.")
+    } else {
+        return binary.BigEndian.Uint64(raw), nil
+    }
+}
+
+func (this Min64) Validate(raw []byte) error {
+    if len(raw) != 8 {
+        return errors.New("Wrong buffer length.")
+    } else {
+        return nil
+    }
+}
+
+func (this Min64) Placeholder() []byte {
+    return this.Encode(^uint64(0))
+}
+
+func (this Min64) Parent(left []byte, right []byte) ([]byte, error) {
+    before, error := this.Before(right, left)
+
+    if error != nil {
+        return []byte{}, error
+    }
+
+    if before {
+        return right, nil
+    } else {
+        return left, nil
+    }
+}
+
+func (this Min64) Navigate(query []byte, parent []byte, left []byte, right []byte) (Navigation, error) {
+    parentbefore, parenterror := this.Before(query, parent)
+
+    if parenterror != nil {
+        return false, parenterror
+    }
+
+    if parentbefore {
+        return false, errors.New("Query precedes parent minimum.")
+    }
+
+    leftbefore, lefterror := this.Before(query, left)
+
+    if lefterror != nil {
+        return false, lefterror
+    }
+
+    if leftbefore {
+        return Right, nil
+    } else {
+        return Left, nil
+    }
+}
+
+func (this Min64) Before(lho []byte, rho []byte) (bool, error) {
+    lhovalue, lhoerror := this.Decode(lho)
+
+    if lhoerror != nil {
+        return false, lhoerror
+    }
+
+    rhovalue, rhoerror := this.Decode(rho)
+
+    if rhoerror != nil {
+        return false, rhoerror
+    }
+
+    return lhovalue.(uint64) < rhovalue.(uint64), nil
+}
@@ -195,3 +195,141 @@ func TestFieldStake64(test *testing.T) {
         test.Error("[field.go]", "[navigate]", "Stake64 navigation does not yield an error on ill-formed input.")
     }
 }
+
+func TestFieldBloom(test *testing.T) {
+    var bloom Bloom
+
+    raw := bloom.Encode([][]byte{[]byte("red"), []byte("green")})
+
+    if len(raw) != bloomsize {
+        test.Error("[field.go]", "[encode]", "Bloom encode() produces a filter of wrong size.")
+    }
+
+    if !(bloom.Test(raw, []byte("red"))) || !(bloom.Test(raw, []byte("green"))) {
+        test.Error("[field.go]", "[test]", "Bloom filter does not contain an encoded tag.")
+    }
+
+    if !equal(bloom.Encode([]byte("red")), bloom.Encode([][]byte{[]byte("red")})) {
+        test.Error("[field.go]", "[encode]", "Bloom encode() differs on a single tag and a slice with one tag.")
+    }
+
+    if bloom.Test(bloom.Placeholder(), []byte("red")) {
+        test.Error("[field.go]", "[placeholder]", "Bloom placeholder contains a tag.")
+    }
+
+    _, decodeerror := bloom.Decode(make([]byte, 3))
+
+    if decodeerror == nil {
+        test.Error("[field.go]", "[decode]", "Bloom decode() does not yield an error on wrong size buffer.")
+    }
+
+    left := bloom.Encode([]byte("red"))
+    right := bloom.Encode([]byte("blue"))
+
+    parent, parenterror := bloom.Parent(left, right)
+
+    if parenterror != nil {
+        test.Error("[field.go]", "[parent]", "Bloom Parent() yields an error on valid filters.")
+    }
+
+    if !(bloom.Test(parent, []byte("red"))) || !(bloom.Test(parent, []byte("blue"))) {
+        test.Error("[field.go]", "[parent]", "Bloom Parent() does not merge children filters.")
+    }
+
+    _, parenterror = bloom.Parent(left, make([]byte, 3))
+
+    if parenterror == nil {
+        test.Error("[field.go]", "[parent]", "Bloom Parent() does not yield an error on ill-formed input.")
+    }
+
+    _, navigateerror := bloom.Navigate(bloom.Encode([]byte("red")), parent, left, right)
+
+    if navigateerror == nil {
+        test.Error("[field.go]", "[navigate]", "Bloom navigation does not yield an error.")
+    }
+
+    if !(bloom.Match(bloom.Encode([]byte("red")), left)) || bloom.Match(bloom.Encode([]byte("blue")), left) || bloom.Match(bloom.Encode([]byte("red")), []byte{}) {
+        test.Error("[field.go]", "[match]", "Bloom Match() does not test the filter for the tag.")
+    }
+
+    collection := EmptyCollection(bloom)
+
+    for index := 0; index < 64; index++ {
+        tag := []byte("even")
+        if index % 2 == 1 {
+            tag = []byte("odd")
+        }
+
+        collection.Add([]byte{byte(index)}, [][]byte{tag, []byte{byte(index)}})
+    }
+
+    collection.Add([]byte("needle"), []byte("needle"))
+
+    record, recorderror := collection.Navigate(0, []byte("needle")).Record()
+
+    if recorderror != nil {
+        test.Error("[field.go]", "[navigate]", "Bloom navigation fails on an existing tag.")
+    }
+
+    if !equal(record.Key(), []byte("needle")) {
+        test.Error("[field.go]", "[navigate]", "Bloom navigation yields the wrong record.")
+    }
+}
+
+func TestFieldMax64Min64(test *testing.T) {
+    var max64 Max64
+    var min64 Min64
+
+    for trial := 0; trial < 64; trial++ {
+        left := rand.Uint64()
+        right := rand.Uint64()
+
+        maxparent, maxerror := max64.Parent(max64.Encode(left), max64.Encode(right))
+        minparent, minerror := min64.Parent(min64.Encode(left), min64.Encode(right))
+
+        if (maxerror != nil) || (minerror != nil) {
+            test.Error("[field.go]", "[parent]", "Max64 or Min64 Parent() yields an error on valid input.")
+        }
+
+        maxvalue, _ := max64.Decode(maxparent)
+        minvalue, _ := min64.Decode(minparent)
+
+        if (left > right && maxvalue.(uint64) != left) || (left <= right && maxvalue.(uint64) != right) {
+            test.Error("[field.go]", "[parent]", "Max64 Parent() is not the maximum of its children.")
+        }
+
+        if (left < right && minvalue.(uint64) != left) || (left >= right && minvalue.(uint64) != right) {
+            test.Error("[field.go]", "[parent]", "Min64 Parent() is not the minimum of its children.")
+        }
+    }
+
+    before, _ := max64.Before(max64.Encode(uint64(3)), max64.Encode(uint64(2)))
+
+    if !before {
+        test.Error("[field.go]", "[before]", "Max64 Before() does not rank larger values first.")
+    }
+
+    before, _ = min64.Before(min64.Encode(uint64(3)), min64.Encode(uint64(2)))
+
+    if before {
+        test.Error("[field.go]", "[before]", "Min64 Before() does not rank smaller values first.")
+    }
+
+    _, wrongsizeerror := max64.Parent(make([]byte, 3), max64.Placeholder())
+
+    if wrongsizeerror == nil {
+        test.Error("[field.go]", "[parent]", "Max64 Parent() does not yield an error on ill-formed input.")
+    }
+
+    _, wrongsizeerror = min64.Navigate(make([]byte, 3), min64.Placeholder(), min64.Placeholder(), min64.Placeholder())
+
+    if wrongsizeerror == nil {
+        test.Error("[field.go]", "[navigate]", "Min64 navigation does not yield an error on ill-formed input.")
+    }
+
+    placeholder, _ := min64.Decode(min64.Placeholder())
+
+    if placeholder.(uint64) != ^uint64(0) {
+        test.Error("[field.go]", "[placeholder]", "Min64 placeholder is not the largest value.")
+    }
+}
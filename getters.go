@@ -16,7 +16,13 @@ func (this *collection) Get(key []byte) getter {
 // Methods
 
 func (this getter) Record() (Record, error) {
-    path := sha256(this.key)
+    error := this.collection.fetch(this.key)
+
+    if error != nil {
+        return Record{}, error
+    }
+
+    path := this.collection.path(this.key)
 
     depth := 0
     cursor := this.collection.root
@@ -27,6 +33,8 @@ func (this getter) Record() (Record, error) {
         }
 
         if cursor.leaf() {
+            this.collection.touch(cursor)
+
             if equal(cursor.key, this.key) {
                 return recordkeymatch(this.collection, cursor), nil
             } else {
@@ -47,12 +55,22 @@ func (this getter) Record() (Record, error) {
 func (this getter) Proof() (Proof, error) {
     var proof Proof
 
+    error := this.collection.fetch(this.key)
+
+    if error != nil {
+        return proof, error
+    }
+
+    if this.collection.uncommitted() {
+        return proof, errors.New("Proof requested on uncommitted data.")
+    }
+
     proof.collection = this.collection
     proof.key = this.key
 
     proof.root = dumpnode(this.collection.root)
 
-    path := sha256(this.key)
+    path := this.collection.path(this.key)
 
     depth := 0
     cursor := this.collection.root
@@ -81,5 +99,7 @@ func (this getter) Proof() (Proof, error) {
         }
     }
 
+    this.collection.touch(cursor)
+
     return proof, nil
 }
@@ -0,0 +1,258 @@
+package collection
+
+import "errors"
+import csha256 "crypto/sha256"
+
+// ConflictError
+
+type ConflictError struct {
+    Keys [][]byte
+}
+
+// Interface
+
+func (this ConflictError) Error() string {
+    return "Transaction conflicts with concurrent changes."
+}
+
+// Handle
+
+type Handle struct {
+    collection *collection
+    version uint64
+    closed bool
+
+    reads map[[csha256.Size]byte][]byte
+    writes map[[csha256.Size]byte]Change
+    order [][csha256.Size]byte
+}
+
+// Constructors
+
+func (this *collection) Handle() Handle {
+    if this.transaction.versions == nil {
+        this.transaction.versions = make(map[[csha256.Size]byte]uint64)
+    }
+
+    this.transaction.handles++
+
+    return Handle{this, this.transaction.clock, false, make(map[[csha256.Size]byte][]byte), make(map[[csha256.Size]byte]Change), [][csha256.Size]byte{}}
+}
+
+// Getters
+
+func (this *Handle) Get(key []byte) (Record, error) {
+    this.open()
+
+    path := this.collection.path(key)
+    this.reads[path] = key
+
+    write, written := this.writes[path]
+
+    if written {
+        if write.New == nil {
+            return recordkeymismatch(this.collection, key), nil
+        }
+
+        return Record{this.collection, 0, []byte{}, true, key, write.New}, nil
+    }
+
+    return this.collection.committedrecord(key)
+}
+
+// Methods
+
+func (this *Handle) Add(key []byte, values... interface{}) error {
+    if len(values) != len(this.collection.fields) {
+        panic("Wrong number of values provided.")
+    }
+
+    rawvalues := make([][]byte, len(this.collection.fields))
+    for index := 0; index < len(this.collection.fields); index++ {
+        rawvalues[index] = this.collection.fields[index].Encode(values[index])
+    }
+
+    error := this.collection.validate(rawvalues)
+
+    if error != nil {
+        return error
+    }
+
+    record, error := this.Get(key)
+
+    if error != nil {
+        return error
+    }
+
+    if record.Match() {
+        return errors.New("Key collision.")
+    }
+
+    this.write(key, rawvalues)
+    return nil
+}
+
+func (this *Handle) Set(key []byte, values... interface{}) error {
+    if len(values) != len(this.collection.fields) {
+        panic("Wrong number of values provided.")
+    }
+
+    record, error := this.Get(key)
+
+    if error != nil {
+        return error
+    }
+
+    if !(record.Match()) {
+        return errors.New("Key not found.")
+    }
+
+    rawvalues := make([][]byte, len(this.collection.fields))
+    for index := 0; index < len(this.collection.fields); index++ {
+        _, same := values[index].(Same)
+
+        if same {
+            rawvalues[index] = record.values[index]
+        } else {
+            rawvalues[index] = this.collection.fields[index].Encode(values[index])
+        }
+    }
+
+    error = this.collection.validate(rawvalues)
+
+    if error != nil {
+        return error
+    }
+
+    this.write(key, rawvalues)
+    return nil
+}
+
+func (this *Handle) SetField(key []byte, field int, value interface{}) error {
+    if field >= len(this.collection.fields) {
+        panic("Field does not exist.")
+    }
+
+    values := make([]interface{}, len(this.collection.fields))
+    for index := 0; index < len(this.collection.fields); index++ {
+        if index == field {
+            values[index] = value
+        } else {
+            values[index] = Same{}
+        }
+    }
+
+    return this.Set(key, values...)
+}
+
+func (this *Handle) Remove(key []byte) error {
+    record, error := this.Get(key)
+
+    if error != nil {
+        return error
+    }
+
+    if !(record.Match()) {
+        return errors.New("Key not found.")
+    }
+
+    this.write(key, nil)
+    return nil
+}
+
+func (this *Handle) Commit() (ChangeSet, error) {
+    this.open()
+
+    if this.collection.transaction.ongoing {
+        panic("Cannot commit a handle while a transaction is ongoing.")
+    }
+
+    conflicts := [][]byte{}
+
+    for path, key := range(this.reads) {
+        if this.collection.transaction.versions[path] > this.version {
+            conflicts = append(conflicts, key)
+        }
+    }
+
+    this.close()
+
+    if len(conflicts) > 0 {
+        return ChangeSet{}, ConflictError{conflicts}
+    }
+
+    this.collection.Begin()
+
+    for _, path := range(this.order) {
+        change := this.writes[path]
+
+        record, error := this.collection.Get(change.Key).Record()
+
+        if error == nil {
+            if change.New == nil {
+                error = this.collection.Remove(change.Key)
+            } else if record.Match() {
+                error = this.collection.set(change.Key, change.New)
+            } else {
+                error = this.collection.add(change.Key, change.New)
+            }
+        }
+
+        if error != nil {
+            this.collection.Rollback()
+
+            if this.collection.AutoCollect.value {
+                this.collection.Collect()
+            }
+
+            return ChangeSet{}, error
+        }
+    }
+
+    return this.collection.End(), nil
+}
+
+func (this *Handle) Discard() {
+    this.open()
+    this.close()
+}
+
+// Private methods
+
+func (this *Handle) open() {
+    if this.closed {
+        panic("Handle already committed or discarded.")
+    }
+}
+
+func (this *Handle) close() {
+    this.closed = true
+    this.collection.transaction.handles--
+
+    if this.collection.transaction.handles == 0 {
+        this.collection.transaction.versions = make(map[[csha256.Size]byte]uint64)
+    }
+}
+
+func (this *Handle) write(key []byte, rawvalues [][]byte) {
+    path := this.collection.path(key)
+
+    if _, written := this.writes[path]; !written {
+        this.order = append(this.order, path)
+    }
+
+    this.writes[path] = Change{Key: key, New: rawvalues}
+}
+
+// collection
+
+// Private methods (collection) (handle)
+
+func (this *collection) bump(key []byte) {
+    if this.transaction.handles == 0 {
+        return
+    }
+
+    this.transaction.clock++
+    this.transaction.versions[this.path(key)] = this.transaction.clock
+}
@@ -0,0 +1,177 @@
+package collection
+
+import "testing"
+
+func TestHandleCommit(test *testing.T) {
+    ctx := testctx("[handle.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    collection.Add([]byte("alice"), uint64(10))
+    collection.Add([]byte("bob"), uint64(20))
+
+    first := collection.Handle()
+    second := collection.Handle()
+
+    first.Set([]byte("alice"), uint64(11))
+    first.Add([]byte("carol"), uint64(30))
+
+    second.Remove([]byte("bob"))
+
+    record, _ := first.Get([]byte("alice"))
+    values, _ := record.Values()
+
+    if !(record.Match()) || (values[0].(uint64) != 11) {
+        test.Error("[handle.go]", "[get]", "Get() does not return the pending writes of the handle.")
+    }
+
+    observer := collection.Handle()
+    record, _ = observer.Get([]byte("alice"))
+    values, _ = record.Values()
+    observer.Discard()
+
+    if values[0].(uint64) != 10 {
+        test.Error("[handle.go]", "[get]", "Get() returns the pending writes of another handle.")
+    }
+
+    record, _ = second.Get([]byte("bob"))
+
+    if record.Match() {
+        test.Error("[handle.go]", "[get]", "Get() finds a key removed by the handle.")
+    }
+
+    if first.Add([]byte("carol"), uint64(31)) == nil {
+        test.Error("[handle.go]", "[add]", "Add() does not yield an error on a key added by the handle.")
+    }
+
+    if second.Set([]byte("bob"), uint64(21)) == nil {
+        test.Error("[handle.go]", "[set]", "Set() does not yield an error on a key removed by the handle.")
+    }
+
+    ctx.verify.values("[unchanged]", &collection, []byte("alice"), uint64(10))
+    ctx.verify.nokey("[unchanged]", &collection, []byte("carol"))
+
+    changeset, error := first.Commit()
+
+    if error != nil {
+        test.Error("[handle.go]", "[commit]", "Commit() yields an error without concurrent changes.")
+    }
+
+    if len(changeset.Changes) != 2 {
+        test.Error("[handle.go]", "[commit]", "Commit() does not return the changes of the handle.")
+    }
+
+    _, error = second.Commit()
+
+    if error != nil {
+        test.Error("[handle.go]", "[commit]", "Commit() yields an error on non-overlapping changes.")
+    }
+
+    ctx.verify.values("[commit]", &collection, []byte("alice"), uint64(11))
+    ctx.verify.values("[commit]", &collection, []byte("carol"), uint64(30))
+    ctx.verify.nokey("[commit]", &collection, []byte("bob"))
+    ctx.verify.tree("[commit]", &collection)
+
+    if (collection.transaction.handles != 0) || (len(collection.transaction.versions) != 0) {
+        test.Error("[handle.go]", "[close]", "Committing every handle does not release the version table.")
+    }
+
+    ctx.should_panic("[closed]", func() {
+        first.Get([]byte("alice"))
+    })
+
+    ctx.should_panic("[closed]", func() {
+        second.Commit()
+    })
+
+    third := collection.Handle()
+    collection.Begin()
+
+    ctx.should_panic("[ongoing]", func() {
+        third.Commit()
+    })
+
+    collection.End()
+    third.Discard()
+
+    ctx.should_panic("[discarded]", func() {
+        third.Discard()
+    })
+}
+
+func TestHandleConflicts(test *testing.T) {
+    ctx := testctx("[handle.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    collection.Add([]byte("alice"), uint64(10))
+    collection.Add([]byte("bob"), uint64(20))
+
+    first := collection.Handle()
+    second := collection.Handle()
+
+    first.SetField([]byte("alice"), 0, uint64(11))
+    second.SetField([]byte("alice"), 0, uint64(12))
+    second.SetField([]byte("bob"), 0, uint64(21))
+
+    first.Commit()
+    _, error := second.Commit()
+
+    conflict, conflicts := error.(ConflictError)
+
+    if !conflicts || (len(conflict.Keys) != 1) || !(equal(conflict.Keys[0], []byte("alice"))) {
+        test.Error("[handle.go]", "[conflict]", "Commit() does not report the conflicting keys of overlapping writes.")
+    }
+
+    ctx.verify.values("[conflict]", &collection, []byte("alice"), uint64(11))
+    ctx.verify.values("[conflict]", &collection, []byte("bob"), uint64(20))
+
+    reader := collection.Handle()
+    reader.Get([]byte("bob"))
+    reader.Add([]byte("carol"), uint64(30))
+
+    collection.Set([]byte("bob"), uint64(22))
+
+    _, error = reader.Commit()
+
+    if _, conflicts := error.(ConflictError); !conflicts {
+        test.Error("[handle.go]", "[conflict]", "Commit() does not detect a direct change to a key read by the handle.")
+    }
+
+    ctx.verify.nokey("[conflict]", &collection, []byte("carol"))
+
+    absent := collection.Handle()
+    absent.Add([]byte("carol"), uint64(30))
+
+    collection.Begin()
+    collection.Add([]byte("carol"), uint64(31))
+
+    concurrent := collection.Handle()
+    record, _ := concurrent.Get([]byte("carol"))
+    concurrent.Discard()
+
+    if record.Match() {
+        test.Error("[handle.go]", "[get]", "Get() returns uncommitted values of an ongoing transaction.")
+    }
+
+    collection.End()
+
+    _, error = absent.Commit()
+
+    if _, conflicts := error.(ConflictError); !conflicts {
+        test.Error("[handle.go]", "[conflict]", "Commit() does not detect a concurrent add of the same key.")
+    }
+
+    rolled := collection.Handle()
+    rolled.Get([]byte("alice"))
+
+    collection.Begin()
+    collection.Set([]byte("alice"), uint64(99))
+    collection.Rollback()
+
+    if _, error := rolled.Commit(); error != nil {
+        test.Error("[handle.go]", "[conflict]", "Commit() reports a conflict on a rolled back change.")
+    }
+}
@@ -0,0 +1,227 @@
+package collection
+
+import "sort"
+
+// indexop
+
+type indexop struct {
+    insert bool
+    value string
+    key []byte
+}
+
+// index
+
+type index struct {
+    field int
+    entries map[string]map[string][]byte
+    log []indexop
+    marks []int
+}
+
+// Constructors
+
+func newindex(field int) *index {
+    return &index{field, make(map[string]map[string][]byte), []indexop{}, []int{}}
+}
+
+// Private methods
+
+func (this *index) insert(value []byte, key []byte) {
+    keys, found := this.entries[string(value)]
+
+    if !found {
+        keys = make(map[string][]byte)
+        this.entries[string(value)] = keys
+    }
+
+    keys[string(key)] = key
+}
+
+func (this *index) remove(value []byte, key []byte) {
+    keys, found := this.entries[string(value)]
+
+    if !found {
+        return
+    }
+
+    delete(keys, string(key))
+
+    if len(keys) == 0 {
+        delete(this.entries, string(value))
+    }
+}
+
+func (this *index) undo() {
+    this.rewind(0)
+
+    this.log = []indexop{}
+    this.marks = []int{}
+}
+
+func (this *index) rewind(length int) {
+    for index := len(this.log) - 1; index >= length; index-- {
+        op := this.log[index]
+
+        if op.insert {
+            this.remove([]byte(op.value), op.key)
+        } else {
+            this.insert([]byte(op.value), op.key)
+        }
+    }
+
+    this.log = this.log[:length]
+}
+
+func (this *index) keys(value []byte) [][]byte {
+    keys := this.entries[string(value)]
+    strings := make([]string, 0, len(keys))
+
+    for key, _ := range(keys) {
+        strings = append(strings, key)
+    }
+
+    sort.Strings(strings)
+
+    result := make([][]byte, len(strings))
+    for index := 0; index < len(strings); index++ {
+        result[index] = keys[strings[index]]
+    }
+
+    return result
+}
+
+// collection
+
+// Methods (collection) (index)
+
+func (this *collection) Index(field int) {
+    if (field < 0) || (field >= len(this.fields)) {
+        panic("Field unknown.")
+    }
+
+    if this.transaction.ongoing {
+        panic("Cannot index a collection while a transaction is ongoing.")
+    }
+
+    if this.indexes == nil {
+        this.indexes = make(map[int]*index)
+    }
+
+    this.indexes[field] = newindex(field)
+    this.indexlearn(this.root)
+}
+
+func (this *collection) Lookup(field int, value interface{}) []getter {
+    index, indexed := this.indexes[field]
+
+    if !indexed {
+        panic("Field not indexed.")
+    }
+
+    keys := index.keys(this.fields[field].Encode(value))
+    getters := make([]getter, len(keys))
+
+    for index := 0; index < len(keys); index++ {
+        getters[index] = this.Get(keys[index])
+    }
+
+    return getters
+}
+
+// Private methods (collection) (index)
+
+func (this *collection) indexinsert(key []byte, values [][]byte) {
+    for _, index := range(this.indexes) {
+        index.insert(values[index.field], key)
+
+        if this.transaction.ongoing {
+            index.log = append(index.log, indexop{true, string(values[index.field]), key})
+        }
+    }
+}
+
+func (this *collection) indexremove(key []byte, values [][]byte) {
+    for _, index := range(this.indexes) {
+        index.remove(values[index.field], key)
+
+        if this.transaction.ongoing {
+            index.log = append(index.log, indexop{false, string(values[index.field]), key})
+        }
+    }
+}
+
+func (this *collection) indexlearn(node *node) {
+    if (len(this.indexes) == 0) || !(node.known) {
+        return
+    }
+
+    if node.leaf() {
+        if !(node.placeholder()) {
+            for _, index := range(this.indexes) {
+                index.insert(node.values[index.field], node.key)
+            }
+        }
+    } else {
+        this.indexlearn(node.children.left)
+        this.indexlearn(node.children.right)
+    }
+}
+
+func (this *collection) indexforget(node *node) {
+    if (len(this.indexes) == 0) || !(node.known) {
+        return
+    }
+
+    if node.leaf() {
+        if !(node.placeholder()) {
+            for _, index := range(this.indexes) {
+                index.remove(node.values[index.field], node.key)
+            }
+        }
+    } else {
+        this.indexforget(node.children.left)
+        this.indexforget(node.children.right)
+    }
+}
+
+func (this *collection) indexundo() {
+    for _, index := range(this.indexes) {
+        index.undo()
+    }
+}
+
+func (this *collection) indexconfirm() {
+    for _, index := range(this.indexes) {
+        index.log = []indexop{}
+        index.marks = []int{}
+    }
+}
+
+func (this *collection) indexmark() {
+    for _, index := range(this.indexes) {
+        index.marks = append(index.marks, len(index.log))
+    }
+}
+
+func (this *collection) indexrewind(savepoint int) {
+    for _, index := range(this.indexes) {
+        index.rewind(index.marks[savepoint - 1])
+        index.marks = index.marks[:savepoint]
+    }
+}
+
+func (this *collection) indexrelease(savepoint int) {
+    for _, index := range(this.indexes) {
+        index.marks = index.marks[:savepoint - 1]
+    }
+}
+
+func (this *collection) reindex() {
+    indexes := this.indexes
+    this.indexes = nil
+
+    for field, _ := range(indexes) {
+        this.Index(field)
+    }
+}
@@ -0,0 +1,225 @@
+package collection
+
+import "testing"
+
+func TestIndexInsertRemove(test *testing.T) {
+    index := newindex(0)
+
+    index.insert([]byte("admin"), []byte("bob"))
+    index.insert([]byte("admin"), []byte("alice"))
+    index.insert([]byte("readonly"), []byte("carol"))
+
+    keys := index.keys([]byte("admin"))
+
+    if (len(keys) != 2) || !equal(keys[0], []byte("alice")) || !equal(keys[1], []byte("bob")) {
+        test.Error("[index.go]", "[keys]", "keys() does not return the sorted keys associated to a value.")
+    }
+
+    index.remove([]byte("admin"), []byte("alice"))
+    index.remove([]byte("admin"), []byte("bob"))
+
+    if len(index.keys([]byte("admin"))) != 0 {
+        test.Error("[index.go]", "[remove]", "remove() does not remove keys from the index.")
+    }
+
+    if len(index.entries) != 1 {
+        test.Error("[index.go]", "[remove]", "remove() leaves empty entries in the index.")
+    }
+
+    index.log = []indexop{{true, "readwrite", []byte("dave")}, {false, "readonly", []byte("carol")}}
+    index.insert([]byte("readwrite"), []byte("dave"))
+    index.remove([]byte("readonly"), []byte("carol"))
+
+    index.undo()
+
+    if (len(index.keys([]byte("readonly"))) != 1) || (len(index.keys([]byte("readwrite"))) != 0) {
+        test.Error("[index.go]", "[undo]", "undo() does not revert logged operations.")
+    }
+
+    if len(index.log) != 0 {
+        test.Error("[index.go]", "[undo]", "undo() does not clear the log.")
+    }
+}
+
+func TestIndexLookup(test *testing.T) {
+    ctx := testctx("[index.go]", test)
+
+    stake64 := Stake64{}
+    data := Data{}
+
+    collection := EmptyCollection(data, stake64)
+
+    collection.Add([]byte("alice"), []byte("admin"), uint64(1))
+    collection.Add([]byte("bob"), []byte("readonly"), uint64(2))
+
+    collection.Index(0)
+
+    collection.Add([]byte("carol"), []byte("admin"), uint64(3))
+    collection.Add([]byte("dave"), []byte("readwrite"), uint64(4))
+
+    getters := collection.Lookup(0, []byte("admin"))
+
+    if (len(getters) != 2) || !equal(getters[0].key, []byte("alice")) || !equal(getters[1].key, []byte("carol")) {
+        test.Error("[index.go]", "[lookup]", "Lookup() does not return the records with the value provided.")
+    }
+
+    verifier := EmptyVerifier(data, stake64)
+    verifier.root.label = collection.root.label
+
+    for _, getter := range(getters) {
+        proof, error := getter.Proof()
+
+        if error != nil {
+            test.Error("[index.go]", "[lookup]", "Lookup() returns getters that cannot produce proofs.")
+        }
+
+        if !(verifier.Verify(proof)) || !(proof.Match()) {
+            test.Error("[index.go]", "[lookup]", "Lookup() returns getters that produce invalid proofs.")
+        }
+    }
+
+    collection.SetField([]byte("bob"), 0, []byte("admin"))
+    collection.Set([]byte("alice"), []byte("readwrite"), uint64(5))
+    collection.Remove([]byte("carol"))
+
+    getters = collection.Lookup(0, []byte("admin"))
+
+    if (len(getters) != 1) || !equal(getters[0].key, []byte("bob")) {
+        test.Error("[index.go]", "[lookup]", "Index is not updated by Set() and Remove().")
+    }
+
+    if len(collection.Lookup(0, []byte("readwrite"))) != 2 {
+        test.Error("[index.go]", "[lookup]", "Index is not updated by Set().")
+    }
+
+    if len(collection.Lookup(0, []byte("readonly"))) != 0 {
+        test.Error("[index.go]", "[lookup]", "Index keeps stale values after Set().")
+    }
+
+    clone := collection.Clone()
+
+    if len(clone.Lookup(0, []byte("readwrite"))) != 2 {
+        test.Error("[index.go]", "[clone]", "Clone() does not rebuild the indexes of the collection.")
+    }
+
+    ctx.should_panic("[lookup]", func() {
+        collection.Lookup(1, uint64(4))
+    })
+
+    ctx.should_panic("[index]", func() {
+        collection.Index(2)
+    })
+}
+
+func TestIndexTransaction(test *testing.T) {
+    ctx := testctx("[index.go]", test)
+
+    data := Data{}
+    collection := EmptyCollection(data)
+    collection.Index(0)
+
+    collection.Add([]byte("alice"), []byte("admin"))
+    collection.Add([]byte("bob"), []byte("readonly"))
+
+    collection.Begin()
+
+    collection.Add([]byte("carol"), []byte("admin"))
+    collection.Set([]byte("bob"), []byte("admin"))
+    collection.Remove([]byte("alice"))
+
+    if len(collection.Lookup(0, []byte("admin"))) != 2 {
+        test.Error("[index.go]", "[transaction]", "Index is not updated during a transaction.")
+    }
+
+    collection.Rollback()
+
+    getters := collection.Lookup(0, []byte("admin"))
+
+    if (len(getters) != 1) || !equal(getters[0].key, []byte("alice")) {
+        test.Error("[index.go]", "[rollback]", "Rollback() does not restore the index.")
+    }
+
+    if len(collection.Lookup(0, []byte("readonly"))) != 1 {
+        test.Error("[index.go]", "[rollback]", "Rollback() does not restore the index.")
+    }
+
+    collection.Begin()
+    collection.Add([]byte("carol"), []byte("admin"))
+    collection.End()
+
+    if len(collection.Lookup(0, []byte("admin"))) != 2 {
+        test.Error("[index.go]", "[end]", "End() does not preserve index updates.")
+    }
+
+    collection.Begin()
+    collection.Rollback()
+
+    if len(collection.Lookup(0, []byte("admin"))) != 2 {
+        test.Error("[index.go]", "[end]", "Rollback() reverts index updates of a committed transaction.")
+    }
+
+    collection.Begin()
+    collection.Add([]byte("dave"), []byte("admin"))
+
+    ctx.should_panic("[ongoing]", func() {
+        collection.Index(0)
+    })
+
+    collection.Rollback()
+
+    for _, getter := range(collection.Lookup(0, []byte("admin"))) {
+        if equal(getter.key, []byte("dave")) {
+            test.Error("[index.go]", "[ongoing]", "Index() keeps a rolled back record.")
+        }
+    }
+}
+
+func TestIndexVerifier(test *testing.T) {
+    data := Data{}
+
+    collection := EmptyCollection(data)
+    collection.Add([]byte("alice"), []byte("admin"))
+    collection.Add([]byte("bob"), []byte("admin"))
+
+    verifier := EmptyVerifier(data)
+    verifier.root.label = collection.root.label
+    verifier.Index(0)
+
+    proof, _ := collection.Get([]byte("alice")).Proof()
+    verifier.Verify(proof)
+
+    getters := verifier.Lookup(0, []byte("admin"))
+
+    if (len(getters) == 0) || !equal(getters[0].key, []byte("alice")) {
+        test.Error("[index.go]", "[verify]", "Verify() does not add verified records to the index.")
+    }
+
+    verifier.Collect()
+
+    if len(verifier.Lookup(0, []byte("admin"))) != 0 {
+        test.Error("[index.go]", "[collect]", "Collect() does not remove pruned records from the index.")
+    }
+}
+
+func TestIndexMigration(test *testing.T) {
+    stake64 := Stake64{}
+    data := Data{}
+
+    collection := EmptyCollection(data, stake64)
+    collection.Index(0)
+    collection.Index(1)
+
+    collection.Add([]byte("alice"), []byte("admin"), uint64(4))
+
+    collection.Migrate(AddField(0, data, nil))
+
+    if len(collection.Lookup(1, []byte("admin"))) != 1 || len(collection.Lookup(2, uint64(4))) != 1 {
+        test.Error("[index.go]", "[migration]", "Migrate() does not shift indexes when adding a field.")
+    }
+
+    collection.Migrate(RemoveField(1))
+
+    if len(collection.indexes) != 1 || len(collection.Lookup(1, uint64(4))) != 1 {
+        test.Error("[index.go]", "[migration]", "Migrate() does not drop and shift indexes when removing a field.")
+    }
+}
@@ -0,0 +1,253 @@
+package collection
+
+import "os"
+import "io"
+import "errors"
+import "hash/crc32"
+import "encoding/binary"
+import csha256 "crypto/sha256"
+import "github.com/dedis/protobuf"
+
+// journalchange
+
+type journalchange struct {
+    Key []byte
+    Values [][]byte
+    Remove bool
+}
+
+// journalrecord
+
+type journalrecord struct {
+    OldRoot [csha256.Size]byte
+    NewRoot [csha256.Size]byte
+    Changes []journalchange
+}
+
+// Journal
+
+type Journal struct {
+    file *os.File
+}
+
+// Constructors
+
+func OpenJournal(path string) (Journal, error) {
+    file, error := os.OpenFile(path, os.O_RDWR | os.O_CREATE, 0600)
+
+    if error != nil {
+        return Journal{}, error
+    }
+
+    journal := Journal{file}
+    error = journal.recover()
+
+    if error != nil {
+        file.Close()
+        return Journal{}, error
+    }
+
+    return journal, nil
+}
+
+// Methods
+
+func (this *Journal) Append(changeset ChangeSet) error {
+    record := journalrecord{changeset.OldRoot, changeset.NewRoot, []journalchange{}}
+
+    for _, change := range(changeset.Changes) {
+        record.Changes = append(record.Changes, journalchange{change.Key, change.New, change.New == nil})
+    }
+
+    payload, error := protobuf.Encode(&record)
+
+    if error != nil {
+        return error
+    }
+
+    buffer := make([]byte, 8 + len(payload))
+    binary.BigEndian.PutUint32(buffer[0:4], uint32(len(payload)))
+    binary.BigEndian.PutUint32(buffer[4:8], crc32.ChecksumIEEE(payload))
+    copy(buffer[8:], payload)
+
+    offset, error := this.file.Seek(0, io.SeekCurrent)
+
+    if error != nil {
+        return error
+    }
+
+    _, error = this.file.Write(buffer)
+
+    if error == nil {
+        error = this.file.Sync()
+    }
+
+    if error != nil {
+        this.file.Truncate(offset)
+        this.file.Seek(offset, io.SeekStart)
+    }
+
+    return error
+}
+
+func (this *Journal) Replay(collection *collection) error {
+    if collection.transaction.ongoing {
+        panic("Cannot replay a journal while a transaction is ongoing.")
+    }
+
+    _, error := this.file.Seek(0, io.SeekStart)
+
+    if error != nil {
+        return error
+    }
+
+    defer this.file.Seek(0, io.SeekEnd)
+
+    if collection.Journal == this {
+        collection.Journal = nil
+
+        defer func() {
+            collection.Journal = this
+        }()
+    }
+
+    for {
+        record, _, error := this.next()
+
+        if error == io.EOF {
+            return nil
+        }
+
+        if error != nil {
+            return error
+        }
+
+        if collection.root.label != record.OldRoot {
+            return errors.New("Journal record does not apply to the collection root.")
+        }
+
+        changes := make([]Change, len(record.Changes))
+
+        for index := 0; index < len(record.Changes); index++ {
+            changes[index].Key = record.Changes[index].Key
+
+            if !(record.Changes[index].Remove) {
+                changes[index].New = [][]byte{}
+
+                for _, value := range(record.Changes[index].Values) {
+                    changes[index].New = append(changes[index].New, append([]byte{}, value...))
+                }
+            }
+        }
+
+        error = collection.applychanges(changes, record.NewRoot)
+
+        if error != nil {
+            return error
+        }
+    }
+}
+
+func (this *Journal) Close() error {
+    return this.file.Close()
+}
+
+// Private methods
+
+func (this *Journal) next() (journalrecord, int64, error) {
+    header := make([]byte, 8)
+    read, error := io.ReadFull(this.file, header)
+
+    if (error == io.EOF) && (read == 0) {
+        return journalrecord{}, 0, io.EOF
+    }
+
+    if error != nil {
+        return journalrecord{}, 0, io.ErrUnexpectedEOF
+    }
+
+    length := int64(binary.BigEndian.Uint32(header[0:4]))
+
+    info, error := this.file.Stat()
+
+    if error != nil {
+        return journalrecord{}, 0, error
+    }
+
+    position, error := this.file.Seek(0, io.SeekCurrent)
+
+    if error != nil {
+        return journalrecord{}, 0, error
+    }
+
+    if length > info.Size() - position {
+        return journalrecord{}, 0, io.ErrUnexpectedEOF
+    }
+
+    payload := make([]byte, length)
+    _, error = io.ReadFull(this.file, payload)
+
+    if error != nil {
+        return journalrecord{}, 0, io.ErrUnexpectedEOF
+    }
+
+    if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+        return journalrecord{}, 8 + length, errors.New("Journal record checksum mismatch.")
+    }
+
+    var record journalrecord
+    error = protobuf.Decode(payload, &record)
+
+    if error != nil {
+        return journalrecord{}, 8 + length, error
+    }
+
+    return record, 8 + length, nil
+}
+
+func (this *Journal) recover() error {
+    info, error := this.file.Stat()
+
+    if error != nil {
+        return error
+    }
+
+    _, error = this.file.Seek(0, io.SeekStart)
+
+    if error != nil {
+        return error
+    }
+
+    var offset int64
+
+    for {
+        _, read, error := this.next()
+
+        if error == io.EOF {
+            break
+        }
+
+        if error != nil {
+            if (error != io.ErrUnexpectedEOF) && (offset + read < info.Size()) {
+                if read == 0 {
+                    return error
+                }
+
+                return errors.New("Journal has a corrupted record followed by further records.")
+            }
+
+            truncateerror := this.file.Truncate(offset)
+
+            if truncateerror != nil {
+                return truncateerror
+            }
+
+            break
+        }
+
+        offset += read
+    }
+
+    _, error = this.file.Seek(offset, io.SeekStart)
+    return error
+}
@@ -0,0 +1,286 @@
+package collection
+
+import "os"
+import "testing"
+import "io/ioutil"
+import "path/filepath"
+
+func TestJournalReplay(test *testing.T) {
+    directory, _ := ioutil.TempDir("", "journal")
+    defer os.RemoveAll(directory)
+
+    path := filepath.Join(directory, "journal")
+
+    stake64 := Stake64{}
+    data := Data{}
+
+    collection := EmptyCollection(stake64, data)
+    journal, error := OpenJournal(path)
+
+    if error != nil {
+        test.Fatal("[journal.go]", "[open]", "OpenJournal() fails on a new file.")
+    }
+
+    for round := 0; round < 4; round++ {
+        collection.Begin()
+
+        for index := 0; index < 16; index++ {
+            key := []byte{byte(round * 16 + index)}
+            collection.Add(key, uint64(index), key)
+        }
+
+        if round > 0 {
+            collection.Remove([]byte{byte(round)})
+            collection.SetField([]byte{byte(round + 1)}, 1, []byte{})
+        }
+
+        journal.Append(collection.End())
+    }
+
+    journal.Close()
+
+    journal, error = OpenJournal(path)
+
+    if error != nil {
+        test.Fatal("[journal.go]", "[open]", "OpenJournal() fails on an existing journal.")
+    }
+
+    replayed := EmptyCollection(stake64, data)
+
+    if journal.Replay(&replayed) != nil {
+        test.Error("[journal.go]", "[replay]", "Replay() fails on a valid journal.")
+    }
+
+    if replayed.root.label != collection.root.label {
+        test.Error("[journal.go]", "[replay]", "Replay() does not rebuild the collection.")
+    }
+
+    if journal.Replay(&replayed) == nil {
+        test.Error("[journal.go]", "[replay]", "Replay() applies records to a collection with a different root.")
+    }
+
+    collection.Begin()
+    collection.Set([]byte{7}, uint64(70), []byte("seventy"))
+    journal.Append(collection.End())
+
+    journal.Close()
+
+    journal, _ = OpenJournal(path)
+    defer journal.Close()
+
+    replayed = EmptyCollection(stake64, data)
+    journal.Replay(&replayed)
+
+    if replayed.root.label != collection.root.label {
+        test.Error("[journal.go]", "[append]", "Append() after Replay() does not extend the journal.")
+    }
+}
+
+func TestJournalRecovery(test *testing.T) {
+    directory, _ := ioutil.TempDir("", "journal")
+    defer os.RemoveAll(directory)
+
+    path := filepath.Join(directory, "journal")
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    journal, _ := OpenJournal(path)
+
+    collection.Begin()
+    collection.Add([]byte("alice"), uint64(1))
+    journal.Append(collection.End())
+
+    committed := collection.root.label
+    info, _ := os.Stat(path)
+    size := info.Size()
+
+    collection.Begin()
+    collection.Add([]byte("bob"), uint64(2))
+    journal.Append(collection.End())
+
+    journal.Close()
+
+    file, _ := os.OpenFile(path, os.O_RDWR, 0600)
+    file.Truncate(size + 11)
+    file.Close()
+
+    journal, error := OpenJournal(path)
+
+    if error != nil {
+        test.Fatal("[journal.go]", "[recover]", "OpenJournal() fails on a journal with a torn tail.")
+    }
+
+    info, _ = os.Stat(path)
+
+    if info.Size() != size {
+        test.Error("[journal.go]", "[recover]", "OpenJournal() does not truncate a torn tail.")
+    }
+
+    replayed := EmptyCollection(stake64)
+
+    if (journal.Replay(&replayed) != nil) || (replayed.root.label != committed) {
+        test.Error("[journal.go]", "[recover]", "Replay() does not rebuild the records before a torn tail.")
+    }
+
+    collection = replayed.Clone()
+
+    collection.Begin()
+    collection.Add([]byte("carol"), uint64(3))
+    journal.Append(collection.End())
+
+    journal.Close()
+
+    raw, _ := ioutil.ReadFile(path)
+    raw[len(raw) - 1] ^= 1
+    ioutil.WriteFile(path, raw, 0600)
+
+    journal, _ = OpenJournal(path)
+    defer journal.Close()
+
+    info, _ = os.Stat(path)
+
+    if info.Size() != size {
+        test.Error("[journal.go]", "[recover]", "OpenJournal() does not truncate a record with a wrong checksum.")
+    }
+
+    replayed = EmptyCollection(stake64)
+
+    if (journal.Replay(&replayed) != nil) || (replayed.root.label != committed) {
+        test.Error("[journal.go]", "[recover]", "Replay() does not skip a record with a wrong checksum.")
+    }
+}
+
+func TestJournalCorruption(test *testing.T) {
+    directory, _ := ioutil.TempDir("", "journal")
+    defer os.RemoveAll(directory)
+
+    path := filepath.Join(directory, "journal")
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    journal, _ := OpenJournal(path)
+
+    collection.Begin()
+    collection.Add([]byte("alice"), uint64(1))
+    journal.Append(collection.End())
+
+    info, _ := os.Stat(path)
+    first := info.Size()
+
+    collection.Begin()
+    collection.Add([]byte("bob"), uint64(2))
+    journal.Append(collection.End())
+
+    collection.Begin()
+    collection.Add([]byte("carol"), uint64(3))
+    journal.Append(collection.End())
+
+    journal.Close()
+
+    raw, _ := ioutil.ReadFile(path)
+    size := len(raw)
+
+    raw[first + 8] ^= 1
+    ioutil.WriteFile(path, raw, 0600)
+
+    _, error := OpenJournal(path)
+
+    if error == nil {
+        test.Error("[journal.go]", "[corruption]", "OpenJournal() does not yield an error on a corrupted record followed by further records.")
+    }
+
+    info, _ = os.Stat(path)
+
+    if info.Size() != int64(size) {
+        test.Error("[journal.go]", "[corruption]", "OpenJournal() discards records following a corrupted record.")
+    }
+}
+
+func TestJournalAttached(test *testing.T) {
+    ctx := testctx("[journal.go]", test)
+
+    directory, _ := ioutil.TempDir("", "journal")
+    defer os.RemoveAll(directory)
+
+    path := filepath.Join(directory, "journal")
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    journal, _ := OpenJournal(path)
+    collection.Journal = &journal
+
+    collection.Begin()
+    collection.Add([]byte("alice"), uint64(1))
+    collection.Add([]byte("bob"), uint64(2))
+    collection.End()
+
+    collection.Set([]byte("alice"), uint64(10))
+    collection.Remove([]byte("bob"))
+
+    if _, error := collection.RemoveIf([]byte("carol"), func(Record) bool { return true }); error != nil {
+        test.Error("[journal.go]", "[attached]", "RemoveIf() yields an error on a missing key with a journal attached.")
+    }
+
+    crashed, error := OpenJournal(path)
+
+    if error != nil {
+        test.Fatal("[journal.go]", "[attached]", "OpenJournal() fails on a journal written by End().")
+    }
+
+    replayed := EmptyCollection(stake64)
+
+    if (crashed.Replay(&replayed) != nil) || (replayed.root.label != collection.root.label) {
+        test.Error("[journal.go]", "[attached]", "End() and manipulations outside a transaction do not reach the journal before returning.")
+    }
+
+    crashed.Close()
+
+    info, _ := os.Stat(path)
+    size := info.Size()
+
+    restored := EmptyCollection(stake64)
+    restored.Journal = &journal
+
+    if (journal.Replay(&restored) != nil) || (restored.Journal != &journal) {
+        test.Error("[journal.go]", "[attached]", "Replay() into a collection attached to the journal does not restore the attachment.")
+    }
+
+    if info, _ = os.Stat(path); info.Size() != size {
+        test.Error("[journal.go]", "[attached]", "Replay() appends the records it replays to the journal.")
+    }
+
+    journal.Close()
+
+    root := collection.root.label
+
+    collection.Begin()
+    collection.Add([]byte("carol"), uint64(3))
+
+    if _, error := collection.Commit(); error == nil {
+        test.Error("[journal.go]", "[attached]", "Commit() does not yield an error when the journal cannot be written.")
+    }
+
+    if collection.transaction.ongoing || (collection.root.label != root) {
+        test.Error("[journal.go]", "[attached]", "Commit() applies a transaction the journal did not record.")
+    }
+
+    ctx.verify.nokey("[attached]", &collection, []byte("carol"))
+
+    if collection.Add([]byte("carol"), uint64(3)) == nil {
+        test.Error("[journal.go]", "[attached]", "Add() outside a transaction succeeds when the journal cannot be written.")
+    }
+
+    collection.Begin()
+    collection.Add([]byte("carol"), uint64(3))
+
+    ctx.should_panic("[attached]", func() {
+        collection.End()
+    })
+
+    if collection.root.label != root {
+        test.Error("[journal.go]", "[attached]", "End() applies a transaction the journal did not record.")
+    }
+}
@@ -0,0 +1,41 @@
+package collection
+
+// limits
+
+type limits struct {
+    depth int
+    steps int
+}
+
+// Methods
+
+func (this *limits) Depth(limit int) {
+    if limit < 1 {
+        panic("Depth limit must be positive.")
+    }
+
+    this.depth = limit
+}
+
+func (this *limits) Steps(limit int) {
+    if limit < 1 {
+        panic("Steps limit must be positive.")
+    }
+
+    this.steps = limit
+}
+
+func (this *limits) Disable() {
+    this.depth = 0
+    this.steps = 0
+}
+
+// Private methods
+
+func (this *limits) deep(depth int) bool {
+    return (this.depth > 0) && (depth > this.depth)
+}
+
+func (this *limits) long(steps int) bool {
+    return (this.steps > 0) && (steps > this.steps)
+}
@@ -0,0 +1,158 @@
+package collection
+
+import "testing"
+import csha256 "crypto/sha256"
+
+func TestLimitsMethods(test *testing.T) {
+    ctx := testctx("[limits.go]", test)
+
+    limits := limits{}
+
+    if limits.deep(1000) || limits.long(1000) {
+        test.Error("[limits.go]", "[limits]", "Zero-valued limits are enforced.")
+    }
+
+    limits.Depth(4)
+    limits.Steps(6)
+
+    if !(limits.deep(5)) || limits.deep(4) || !(limits.long(7)) || limits.long(6) {
+        test.Error("[limits.go]", "[limits]", "Depth() and Steps() do not set the limits provided.")
+    }
+
+    limits.Disable()
+
+    if limits.deep(5) || limits.long(7) {
+        test.Error("[limits.go]", "[disable]", "Disable() does not lift the limits.")
+    }
+
+    ctx.should_panic("[depth]", func() {
+        limits.Depth(0)
+    })
+
+    ctx.should_panic("[steps]", func() {
+        limits.Steps(-1)
+    })
+}
+
+func TestLimitsDepth(test *testing.T) {
+    ctx := testctx("[limits.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+    collection.Limits.Depth(4)
+
+    failures := 0
+
+    for index := 0; index < 64; index++ {
+        if collection.Add([]byte{byte(index)}, uint64(index)) != nil {
+            failures++
+            ctx.verify.nokey("[add]", &collection, []byte{byte(index)})
+        }
+    }
+
+    if failures == 0 {
+        test.Error("[limits.go]", "[add]", "Add() exceeds the maximum depth.")
+    }
+
+    ctx.verify.tree("[add]", &collection)
+
+    if collection.Stats().MaxDepth > 4 {
+        test.Error("[limits.go]", "[add]", "Add() builds a tree deeper than the maximum depth.")
+    }
+
+    collection.Begin()
+
+    for index := 64; index < 128; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    collection.End()
+
+    ctx.verify.tree("[transaction]", &collection)
+
+    if collection.Stats().MaxDepth > 4 {
+        test.Error("[limits.go]", "[transaction]", "Add() builds a tree deeper than the maximum depth during a transaction.")
+    }
+}
+
+func TestLimitsSteps(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 512; index++ {
+        collection.Add([]byte{byte(index), byte(index >> 8)}, uint64(index))
+    }
+
+    var deepest []byte
+    var longest Proof
+
+    for index := 0; index < 512; index++ {
+        key := []byte{byte(index), byte(index >> 8)}
+        proof, _ := collection.Get(key).Proof()
+
+        if len(proof.steps) > len(longest.steps) {
+            deepest = key
+            longest = proof
+        }
+    }
+
+    verifier := EmptyVerifier(stake64)
+    verifier.root.label = collection.root.label
+    verifier.Limits.Steps(len(longest.steps) - 1)
+
+    if verifier.Verify(longest) {
+        test.Error("[limits.go]", "[verify]", "Verify() accepts a proof with too many steps.")
+    }
+
+    if _, error := verifier.Deserialize(collection.Serialize(longest)); error == nil {
+        test.Error("[limits.go]", "[deserialize]", "Deserialize() accepts a proof with too many steps.")
+    }
+
+    verifier.Limits.Steps(len(longest.steps))
+
+    if !(verifier.Verify(longest)) {
+        test.Error("[limits.go]", "[verify]", "Verify() rejects a proof within the limits.")
+    }
+
+    if _, error := verifier.Get(deepest).Record(); error != nil {
+        test.Error("[limits.go]", "[verify]", "Verify() does not learn a proof within the limits.")
+    }
+
+    verifier.Limits.Disable()
+    verifier.Limits.Depth(len(longest.steps) - 1)
+
+    if _, error := verifier.Deserialize(collection.Serialize(longest)); error == nil {
+        test.Error("[limits.go]", "[deserialize]", "Deserialize() accepts a proof deeper than the maximum depth.")
+    }
+}
+
+func TestLimitsPathBits(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 16; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    proof, _ := collection.Get([]byte{3}).Proof()
+
+    for len(proof.steps) <= 8 * csha256.Size {
+        proof.steps = append(proof.steps, proof.steps[len(proof.steps) - 1])
+    }
+
+    verifier := EmptyVerifier(stake64)
+    verifier.root.label = collection.root.label
+    verifier.Limits.Disable()
+
+    if _, error := verifier.Deserialize(collection.Serialize(proof)); error == nil {
+        test.Error("[limits.go]", "[deserialize]", "Deserialize() accepts a proof with more steps than path bits.")
+    }
+
+    if verifier.Verify(proof) {
+        test.Error("[limits.go]", "[verify]", "Verify() accepts a proof with more steps than path bits.")
+    }
+
+    if collection.Verify(proof) {
+        test.Error("[limits.go]", "[verify]", "Verify() accepts a proof with more steps than path bits on a known root.")
+    }
+}
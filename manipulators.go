@@ -12,201 +12,344 @@ func (this *collection) Add(key []byte, values... interface{}) error {
         panic("Wrong number of values provided.")
     }
 
+    if (this.Journal != nil) && !(this.transaction.ongoing) {
+        return this.journaled(func() error {
+            return this.Add(key, values...)
+        })
+    }
+
     rawvalues := make([][]byte, len(this.fields))
     for index := 0; index < len(this.fields); index++ {
         rawvalues[index] = this.fields[index].Encode(values[index])
     }
 
-    path := sha256(key)
+    return this.add(key, rawvalues)
+}
 
-    depth := 0
-    cursor := this.root
+func (this *collection) Set(key []byte, values... interface{}) error {
+    if len(values) != len(this.fields) {
+        panic("Wrong number of values provided.")
+    }
 
-    if !(cursor.known) {
-        return errors.New("Applying update to unknown subtree. Proof needed.")
+    if (this.Journal != nil) && !(this.transaction.ongoing) {
+        return this.journaled(func() error {
+            return this.Set(key, values...)
+        })
     }
 
-    for {
-        if !(cursor.children.left.known) || !(cursor.children.right.known) {
-            return errors.New("Applying update to unknown subtree. Proof needed.")
-        }
+    return this.set(key, this.encode(values))
+}
 
-        step := bit(path[:], depth)
-        depth++
+func (this *collection) SetField(key []byte, field int, value interface{}) error {
+    if field >= len(this.fields) {
+        panic("Field does not exist.")
+    }
 
-        if step {
-            cursor = cursor.children.right
+    values := make([]interface{}, len(this.fields))
+    for index := 0; index < len(this.fields); index++ {
+        if index == field {
+            values[index] = value
         } else {
-            cursor = cursor.children.left
+            values[index] = Same{}
         }
+    }
 
-        if cursor.placeholder() {
-            if this.transaction.ongoing {
-                cursor.backup()
-            }
+    return this.Set(key, values...)
+}
 
-            cursor.key = key
-            cursor.values = rawvalues
-            this.update(cursor)
+func (this *collection) Remove(key []byte) error {
+    if (this.Journal != nil) && !(this.transaction.ongoing) {
+        return this.journaled(func() error {
+            return this.Remove(key)
+        })
+    }
 
-            break
-        } else if cursor.leaf() {
-            if equal(key, cursor.key) {
-                return errors.New("Key collision.")
-            }
+    error := this.fetch(key)
 
-            collision := *cursor
-            collisionpath := sha256(collision.key)
-            collisionstep := bit(collisionpath[:], depth)
+    if error != nil {
+        return error
+    }
 
-            if this.transaction.ongoing {
-                cursor.backup()
-            }
+    cursor, _, error := this.walk(key)
 
-            cursor.key = []byte{}
-            cursor.branch()
+    if error != nil {
+        return error
+    }
 
-            if collisionstep {
-                cursor.children.right.known = true
-                cursor.children.right.label = collision.label
-                cursor.children.right.key = collision.key
-                cursor.children.right.values = collision.values
+    if !(equal(cursor.key, key)) {
+        return errors.New("Key not found.")
+    }
 
-                this.placeholder(cursor.children.left)
-            } else {
-                cursor.children.left.known = true
-                cursor.children.left.label = collision.label
-                cursor.children.left.key = collision.key
-                cursor.children.left.values = collision.values
+    this.remove(cursor, key)
+    return nil
+}
 
-                this.placeholder(cursor.children.right)
-            }
-        }
+func (this *collection) Upsert(key []byte, values... interface{}) error {
+    if len(values) != len(this.fields) {
+        panic("Wrong number of values provided.")
     }
 
-    for {
-        if cursor.parent == nil {
-            break
-        }
+    if (this.Journal != nil) && !(this.transaction.ongoing) {
+        return this.journaled(func() error {
+            return this.Upsert(key, values...)
+        })
+    }
 
-        cursor = cursor.parent
+    rawvalues := this.encode(values)
+    error := this.validatepartial(rawvalues)
 
-        if this.transaction.ongoing {
-            cursor.transaction.inconsistent = true
-        } else {
-            this.update(cursor)
-        }
+    if error != nil {
+        return error
     }
 
-    if !(this.transaction.ongoing) {
-        this.Collect()
+    error = this.fetch(key)
+
+    if error != nil {
+        return error
     }
 
-    return nil
+    cursor, depth, error := this.walk(key)
+
+    if error != nil {
+        return error
+    }
+
+    if !(cursor.placeholder()) && equal(cursor.key, key) {
+        this.replace(cursor, key, rawvalues)
+        return nil
+    }
+
+    if !(this.complete(rawvalues)) {
+        return errors.New("Cannot keep values of a missing key.")
+    }
+
+    return this.insert(cursor, depth, key, rawvalues)
 }
 
-func (this *collection) Set(key []byte, values... interface{}) error {
-    if len(values) != len(this.fields) {
+func (this *collection) CompareAndSet(key []byte, expected []interface{}, values... interface{}) error {
+    if (len(values) != len(this.fields)) || ((expected != nil) && (len(expected) != len(this.fields))) {
         panic("Wrong number of values provided.")
     }
 
-    path := sha256(key)
+    if (this.Journal != nil) && !(this.transaction.ongoing) {
+        return this.journaled(func() error {
+            return this.CompareAndSet(key, expected, values...)
+        })
+    }
 
-    depth := 0
-    cursor := this.root
+    rawvalues := this.encode(values)
+    error := this.validatepartial(rawvalues)
 
-    if !(cursor.known) {
-        return errors.New("Applying update to unknown subtree. Proof needed.")
+    if error != nil {
+        return error
     }
 
-    for {
-        if !(cursor.children.left.known) || !(cursor.children.right.known) {
-            return errors.New("Applying update to unknown subtree. Proof needed.")
-        }
+    error = this.fetch(key)
 
-        step := bit(path[:], depth)
-        depth++
+    if error != nil {
+        return error
+    }
 
-        if step {
-            cursor = cursor.children.right
-        } else {
-            cursor = cursor.children.left
+    cursor, depth, error := this.walk(key)
+
+    if error != nil {
+        return error
+    }
+
+    match := !(cursor.placeholder()) && equal(cursor.key, key)
+
+    if expected == nil {
+        if match {
+            return errors.New("Compare and set failed.")
         }
 
-        if cursor.leaf() {
-            if !(equal(cursor.key, key)) {
-                return errors.New("Key not found.")
-            } else {
-                if this.transaction.ongoing {
-                    cursor.backup()
-                }
+        if !(this.complete(rawvalues)) {
+            return errors.New("Cannot keep values of a missing key.")
+        }
 
-                for index := 0; index < len(this.fields); index++ {
-                    _, same := values[index].(Same)
+        return this.insert(cursor, depth, key, rawvalues)
+    }
 
-                    if !same {
-                        cursor.values[index] = this.fields[index].Encode(values[index])
-                    }
-                }
+    if !match {
+        return errors.New("Compare and set failed.")
+    }
 
-                this.update(cursor)
+    rawexpected := this.encode(expected)
 
-                break
-            }
+    for index := 0; index < len(this.fields); index++ {
+        if (rawexpected[index] != nil) && !(equal(rawexpected[index], cursor.values[index])) {
+            return errors.New("Compare and set failed.")
         }
     }
 
-    for {
-        if cursor.parent == nil {
-            break
-        }
+    this.replace(cursor, key, rawvalues)
+    return nil
+}
 
-        cursor = cursor.parent
+func (this *collection) RemoveIf(key []byte, predicate func(Record) bool) (bool, error) {
+    if (this.Journal != nil) && !(this.transaction.ongoing) {
+        removed := false
 
-        if this.transaction.ongoing {
-            cursor.transaction.inconsistent = true
-        } else {
-            this.update(cursor)
+        error := this.journaled(func() error {
+            var error error
+            removed, error = this.RemoveIf(key, predicate)
+
+            return error
+        })
+
+        return removed, error
+    }
+
+    error := this.fetch(key)
+
+    if error != nil {
+        return false, error
+    }
+
+    cursor, _, error := this.walk(key)
+
+    if error != nil {
+        return false, error
+    }
+
+    if cursor.placeholder() || !(equal(cursor.key, key)) || !(predicate(recordkeymatch(this, cursor))) {
+        return false, nil
+    }
+
+    this.remove(cursor, key)
+    return true, nil
+}
+
+// Private methods (collection) (manipulators)
+
+func (this *collection) journaled(manipulation func() error) error {
+    this.Begin()
+    error := manipulation()
+
+    if error != nil {
+        this.Rollback()
+
+        if this.AutoCollect.value {
+            this.Collect()
         }
+
+        return error
     }
 
-    if !(this.transaction.ongoing) {
-        this.Collect()
+    _, error = this.Commit()
+    return error
+}
+
+func (this *collection) add(key []byte, rawvalues [][]byte) error {
+    error := this.validate(rawvalues)
+
+    if error != nil {
+        return error
     }
 
+    error = this.fetch(key)
+
+    if error != nil {
+        return error
+    }
+
+    cursor, depth, error := this.walk(key)
+
+    if error != nil {
+        return error
+    }
+
+    if !(cursor.placeholder()) && equal(key, cursor.key) {
+        return errors.New("Key collision.")
+    }
+
+    return this.insert(cursor, depth, key, rawvalues)
+}
+
+func (this *collection) set(key []byte, rawvalues [][]byte) error {
+    error := this.validatepartial(rawvalues)
+
+    if error != nil {
+        return error
+    }
+
+    error = this.fetch(key)
+
+    if error != nil {
+        return error
+    }
+
+    cursor, _, error := this.walk(key)
+
+    if error != nil {
+        return error
+    }
+
+    if !(equal(cursor.key, key)) {
+        return errors.New("Key not found.")
+    }
+
+    this.replace(cursor, key, rawvalues)
     return nil
 }
 
-func (this *collection) SetField(key []byte, field int, value interface{}) error {
-    if field >= len(this.fields) {
-        panic("Field does not exist.")
+func (this *collection) encode(values []interface{}) [][]byte {
+    rawvalues := make([][]byte, len(this.fields))
+
+    for index := 0; index < len(this.fields); index++ {
+        _, same := values[index].(Same)
+
+        if !same {
+            rawvalues[index] = this.fields[index].Encode(values[index])
+        }
+    }
+
+    return rawvalues
+}
+
+func (this *collection) complete(rawvalues [][]byte) bool {
+    for index := 0; index < len(rawvalues); index++ {
+        if rawvalues[index] == nil {
+            return false
+        }
+    }
+
+    return true
+}
+
+func (this *collection) validatepartial(rawvalues [][]byte) error {
+    if len(rawvalues) != len(this.fields) {
+        return errors.New("Wrong number of values.")
     }
 
-    values := make([]interface{}, len(this.fields))
     for index := 0; index < len(this.fields); index++ {
-        if index == field {
-            values[index] = value
-        } else {
-            values[index] = Same{}
+        validator, validates := this.fields[index].(Validator)
+
+        if validates && (rawvalues[index] != nil) {
+            error := validator.Validate(rawvalues[index])
+
+            if error != nil {
+                return error
+            }
         }
     }
 
-    return this.Set(key, values...)
+    return nil
 }
 
-func (this *collection) Remove(key []byte) error {
-    path := sha256(key)
+func (this *collection) walk(key []byte) (*node, int, error) {
+    path := this.path(key)
 
     depth := 0
     cursor := this.root
 
     if !(cursor.known) {
-        return errors.New("Applying update to unknown subtree. Proof needed.")
+        return nil, 0, errors.New("Applying update to unknown subtree. Proof needed.")
     }
 
     for {
         if !(cursor.children.left.known) || !(cursor.children.right.known) {
-            return errors.New("Applying update to unknown subtree. Proof needed.")
+            return nil, 0, errors.New("Applying update to unknown subtree. Proof needed.")
         }
 
         step := bit(path[:], depth)
@@ -219,19 +362,118 @@ func (this *collection) Remove(key []byte) error {
         }
 
         if cursor.leaf() {
-            if !(equal(cursor.key, key)) {
-                return errors.New("Key not found.")
-            } else {
-                if this.transaction.ongoing {
-                    cursor.backup()
-                }
+            return cursor, depth, nil
+        }
+    }
+}
 
-                this.placeholder(cursor)
-                break
+func (this *collection) insert(cursor *node, depth int, key []byte, rawvalues [][]byte) error {
+    path := this.path(key)
+
+    if !(cursor.placeholder()) {
+        collisionpath := this.path(cursor.key)
+        shared := depth
+
+        for (shared < 8 * len(path)) && (bit(path[:], shared) == bit(collisionpath[:], shared)) {
+            shared++
+        }
+
+        if this.Limits.deep(shared + 1) {
+            return errors.New("Maximum depth exceeded.")
+        }
+    }
+
+    for {
+        if cursor.placeholder() {
+            if this.transaction.ongoing {
+                cursor.checkpoint(this.transaction.savepoints)
             }
+
+            cursor.key = key
+            cursor.values = rawvalues
+            this.update(cursor)
+
+            this.indexinsert(key, rawvalues)
+
+            break
         }
+
+        collision := *cursor
+        collisionpath := this.path(collision.key)
+        collisionstep := bit(collisionpath[:], depth)
+
+        if this.transaction.ongoing {
+            cursor.checkpoint(this.transaction.savepoints)
+        }
+
+        cursor.key = []byte{}
+        cursor.branch()
+
+        if collisionstep {
+            cursor.children.right.known = true
+            cursor.children.right.label = collision.label
+            cursor.children.right.key = collision.key
+            cursor.children.right.values = make([][]byte, len(collision.values))
+            copy(cursor.children.right.values, collision.values)
+
+            this.placeholder(cursor.children.left)
+        } else {
+            cursor.children.left.known = true
+            cursor.children.left.label = collision.label
+            cursor.children.left.key = collision.key
+            cursor.children.left.values = make([][]byte, len(collision.values))
+            copy(cursor.children.left.values, collision.values)
+
+            this.placeholder(cursor.children.right)
+        }
+
+        if bit(path[:], depth) {
+            cursor = cursor.children.right
+        } else {
+            cursor = cursor.children.left
+        }
+
+        depth++
+    }
+
+    this.climb(cursor, key)
+    return nil
+}
+
+func (this *collection) replace(cursor *node, key []byte, rawvalues [][]byte) {
+    if this.transaction.ongoing {
+        cursor.checkpoint(this.transaction.savepoints)
     }
 
+    this.indexremove(key, cursor.values)
+
+    values := make([][]byte, len(cursor.values))
+    copy(values, cursor.values)
+
+    for index := 0; index < len(this.fields); index++ {
+        if rawvalues[index] != nil {
+            values[index] = rawvalues[index]
+        }
+    }
+
+    cursor.values = values
+
+    this.update(cursor)
+    this.indexinsert(key, cursor.values)
+
+    this.climb(cursor, key)
+}
+
+func (this *collection) remove(cursor *node, key []byte) {
+    if this.transaction.ongoing {
+        cursor.checkpoint(this.transaction.savepoints)
+    }
+
+    this.indexremove(key, cursor.values)
+    this.placeholder(cursor)
+
+    this.touch(cursor)
+
     for {
         if cursor.parent == nil {
             break
@@ -241,17 +483,19 @@ func (this *collection) Remove(key []byte) error {
 
         if (cursor.parent != nil) && ((cursor.children.left.placeholder() && cursor.children.right.leaf()) || (cursor.children.right.placeholder() && cursor.children.left.leaf())) {
             if this.transaction.ongoing {
-                cursor.backup()
+                cursor.checkpoint(this.transaction.savepoints)
             }
 
             if cursor.children.left.placeholder() {
                 cursor.label = cursor.children.right.label
                 cursor.key = cursor.children.right.key
-                cursor.values = cursor.children.right.values
+                cursor.values = make([][]byte, len(cursor.children.right.values))
+                copy(cursor.values, cursor.children.right.values)
             } else {
                 cursor.label = cursor.children.left.label
                 cursor.key = cursor.children.left.key
-                cursor.values = cursor.children.left.values
+                cursor.values = make([][]byte, len(cursor.children.left.values))
+                copy(cursor.values, cursor.children.left.values)
             }
 
             cursor.prune()
@@ -265,8 +509,30 @@ func (this *collection) Remove(key []byte) error {
     }
 
     if !(this.transaction.ongoing) {
+        this.bump(key)
         this.Collect()
     }
+}
 
-    return nil
+func (this *collection) climb(cursor *node, key []byte) {
+    this.touch(cursor)
+
+    for {
+        if cursor.parent == nil {
+            break
+        }
+
+        cursor = cursor.parent
+
+        if this.transaction.ongoing {
+            cursor.transaction.inconsistent = true
+        } else {
+            this.update(cursor)
+        }
+    }
+
+    if !(this.transaction.ongoing) {
+        this.bump(key)
+        this.Collect()
+    }
 }
@@ -310,3 +310,168 @@ func TestManipulatorsRemove(test *testing.T) {
         test.Error("[manipulators.go]", "[transaction]", "Transaction on collection doesn't produce empty root after removing all records.")
     }
 }
+
+func TestManipulatorsUpsert(test *testing.T) {
+    ctx := testctx("[manipulators.go]", test)
+
+    stake64 := Stake64{}
+    data := Data{}
+
+    collection := EmptyCollection(stake64, data)
+    reference := EmptyCollection(stake64, data)
+
+    for index := 0; index < 256; index++ {
+        key := []byte{byte(index)}
+
+        if collection.Upsert(key, uint64(index), key) != nil {
+            test.Error("[manipulators.go]", "[upsert]", "Upsert() yields an error on a missing key.")
+        }
+
+        reference.Add(key, uint64(index), key)
+    }
+
+    for index := 0; index < 256; index += 3 {
+        key := []byte{byte(index)}
+
+        if collection.Upsert(key, uint64(2 * index), Same{}) != nil {
+            test.Error("[manipulators.go]", "[upsert]", "Upsert() yields an error on an existing key.")
+        }
+
+        reference.SetField(key, 0, uint64(2 * index))
+    }
+
+    if collection.root.label != reference.root.label {
+        test.Error("[manipulators.go]", "[upsert]", "Upsert() does not yield the same root as Add() and Set().")
+    }
+
+    ctx.verify.tree("[upsert]", &collection)
+    ctx.verify.values("[upsert]", &collection, []byte{3}, uint64(6), []byte{3})
+
+    if collection.Upsert([]byte("missing"), uint64(1), Same{}) == nil {
+        test.Error("[manipulators.go]", "[upsert]", "Upsert() does not yield an error when keeping values of a missing key.")
+    }
+
+    ctx.verify.nokey("[upsert]", &collection, []byte("missing"))
+
+    collection.Begin()
+    collection.Upsert([]byte{1}, uint64(100), []byte("one"))
+    collection.Upsert([]byte("new"), uint64(200), []byte("two"))
+    collection.Rollback()
+
+    if collection.root.label != reference.root.label {
+        test.Error("[manipulators.go]", "[transaction]", "Rollback() does not revert Upsert().")
+    }
+
+    unknownroot := EmptyCollection(stake64, data)
+    unknownroot.root.known = false
+
+    if unknownroot.Upsert([]byte("key"), uint64(1), []byte{}) == nil {
+        test.Error("[manipulators.go]", "[unknownroot]", "Upsert() does not yield an error on a collection with unknown root.")
+    }
+
+    ctx.should_panic("[values]", func() {
+        collection.Upsert([]byte("key"), uint64(1))
+    })
+}
+
+func TestManipulatorsCompareAndSet(test *testing.T) {
+    ctx := testctx("[manipulators.go]", test)
+
+    stake64 := Stake64{}
+    data := Data{}
+
+    collection := EmptyCollection(stake64, data)
+    collection.Add([]byte("alice"), uint64(10), []byte("a"))
+
+    if collection.CompareAndSet([]byte("alice"), []interface{}{uint64(11), Same{}}, uint64(12), Same{}) == nil {
+        test.Error("[manipulators.go]", "[compareandset]", "CompareAndSet() does not yield an error on mismatching values.")
+    }
+
+    ctx.verify.values("[compareandset]", &collection, []byte("alice"), uint64(10), []byte("a"))
+
+    if collection.CompareAndSet([]byte("alice"), []interface{}{uint64(10), Same{}}, uint64(12), Same{}) != nil {
+        test.Error("[manipulators.go]", "[compareandset]", "CompareAndSet() yields an error on matching values.")
+    }
+
+    ctx.verify.values("[compareandset]", &collection, []byte("alice"), uint64(12), []byte("a"))
+
+    if collection.CompareAndSet([]byte("alice"), []interface{}{uint64(12), []byte("a")}, Same{}, []byte("b")) != nil {
+        test.Error("[manipulators.go]", "[compareandset]", "CompareAndSet() yields an error when all values match.")
+    }
+
+    ctx.verify.values("[compareandset]", &collection, []byte("alice"), uint64(12), []byte("b"))
+
+    if collection.CompareAndSet([]byte("alice"), nil, uint64(1), []byte{}) == nil {
+        test.Error("[manipulators.go]", "[compareandset]", "CompareAndSet() does not yield an error when expecting an existing key to be missing.")
+    }
+
+    if collection.CompareAndSet([]byte("bob"), []interface{}{Same{}, Same{}}, uint64(1), []byte{}) == nil {
+        test.Error("[manipulators.go]", "[compareandset]", "CompareAndSet() does not yield an error when expecting a missing key to exist.")
+    }
+
+    if collection.CompareAndSet([]byte("bob"), nil, uint64(20), []byte("b")) != nil {
+        test.Error("[manipulators.go]", "[compareandset]", "CompareAndSet() yields an error when adding a missing key.")
+    }
+
+    ctx.verify.values("[compareandset]", &collection, []byte("bob"), uint64(20), []byte("b"))
+    ctx.verify.tree("[compareandset]", &collection)
+
+    if collection.CompareAndSet([]byte("carol"), nil, uint64(30), Same{}) == nil {
+        test.Error("[manipulators.go]", "[compareandset]", "CompareAndSet() does not yield an error when keeping values of a missing key.")
+    }
+
+    ctx.should_panic("[values]", func() {
+        collection.CompareAndSet([]byte("alice"), []interface{}{uint64(12)}, uint64(1), []byte{})
+    })
+}
+
+func TestManipulatorsRemoveIf(test *testing.T) {
+    ctx := testctx("[manipulators.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 64; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    even := func(record Record) bool {
+        values, _ := record.Values()
+        return values[0].(uint64) % 2 == 0
+    }
+
+    for index := 0; index < 64; index++ {
+        removed, error := collection.RemoveIf([]byte{byte(index)}, even)
+
+        if (error != nil) || (removed != (index % 2 == 0)) {
+            test.Error("[manipulators.go]", "[removeif]", "RemoveIf() does not remove exactly the records matching the predicate.")
+        }
+    }
+
+    for index := 0; index < 64; index++ {
+        if index % 2 == 0 {
+            ctx.verify.nokey("[removeif]", &collection, []byte{byte(index)})
+        } else {
+            ctx.verify.key("[removeif]", &collection, []byte{byte(index)})
+        }
+    }
+
+    ctx.verify.tree("[removeif]", &collection)
+
+    called := false
+    removed, error := collection.RemoveIf([]byte("missing"), func(record Record) bool {
+        called = true
+        return true
+    })
+
+    if removed || (error != nil) || called {
+        test.Error("[manipulators.go]", "[removeif]", "RemoveIf() calls the predicate or yields an error on a missing key.")
+    }
+
+    unknownroot := EmptyCollection(stake64)
+    unknownroot.root.known = false
+
+    if _, error := unknownroot.RemoveIf([]byte("key"), even); error == nil {
+        test.Error("[manipulators.go]", "[unknownroot]", "RemoveIf() does not yield an error on a collection with unknown root.")
+    }
+}
@@ -0,0 +1,276 @@
+package collection
+
+import "errors"
+
+// Structs
+
+type Migration struct {
+    index int
+    remove bool
+
+    field Field
+    value func(Record) interface{}
+
+    proofs []Proof
+}
+
+// Constructors
+
+func AddField(index int, field Field, value func(Record) interface{}) Migration {
+    return Migration{index, false, field, value, []Proof{}}
+}
+
+func RemoveField(index int) Migration {
+    return Migration{index, true, nil, nil, []Proof{}}
+}
+
+// Getters
+
+func (this Migration) Records() []Proof {
+    return this.proofs
+}
+
+// Private methods
+
+func (this Migration) fields(fields []Field) []Field {
+    migrated := make([]Field, 0, len(fields) + 1)
+    migrated = append(migrated, fields[:this.index]...)
+
+    if this.remove {
+        migrated = append(migrated, fields[this.index + 1:]...)
+    } else {
+        migrated = append(migrated, this.field)
+        migrated = append(migrated, fields[this.index:]...)
+    }
+
+    return migrated
+}
+
+func (this Migration) values(values [][]byte, value []byte) [][]byte {
+    migrated := make([][]byte, 0, len(values) + 1)
+    migrated = append(migrated, values[:this.index]...)
+
+    if this.remove {
+        migrated = append(migrated, values[this.index + 1:]...)
+    } else {
+        migrated = append(migrated, value)
+        migrated = append(migrated, values[this.index:]...)
+    }
+
+    return migrated
+}
+
+// collection
+
+// Methods (collection) (migration)
+
+func (this *collection) Prove(migration Migration) (Migration, error) {
+    var keys [][]byte
+
+    var explore func(*node) error
+    explore = func(node *node) error {
+        if !(node.known) {
+            return errors.New("Migrating unknown subtree. Proof needed.")
+        }
+
+        if node.leaf() {
+            if !(node.placeholder()) {
+                keys = append(keys, node.key)
+            }
+
+            return nil
+        }
+
+        lefterror := explore(node.children.left)
+
+        if lefterror != nil {
+            return lefterror
+        }
+
+        return explore(node.children.right)
+    }
+
+    error := explore(this.root)
+
+    if error != nil {
+        return Migration{}, error
+    }
+
+    if len(keys) == 0 {
+        keys = append(keys, []byte{})
+    }
+
+    migration.proofs = make([]Proof, len(keys))
+
+    for index := 0; index < len(keys); index++ {
+        proof, prooferror := this.Get(keys[index]).Proof()
+
+        if prooferror != nil {
+            return Migration{}, prooferror
+        }
+
+        migration.proofs[index] = proof
+    }
+
+    return migration, nil
+}
+
+func (this *collection) Migrate(migration Migration) error {
+    if this.transaction.ongoing {
+        panic("Cannot migrate a collection while a transaction is ongoing.")
+    }
+
+    if migration.remove {
+        if (migration.index < 0) || (migration.index >= len(this.fields)) {
+            panic("Field unknown.")
+        }
+    } else {
+        if (migration.index < 0) || (migration.index > len(this.fields)) {
+            panic("Field index out of range.")
+        }
+    }
+
+    var known func(*node) bool
+    known = func(node *node) bool {
+        if !(node.known) {
+            return false
+        }
+
+        return node.leaf() || (known(node.children.left) && known(node.children.right))
+    }
+
+    if !(known(this.root)) {
+        return errors.New("Migrating unknown subtree. Proof needed.")
+    }
+
+    this.Begin()
+
+    var validator Validator
+    validates := false
+
+    if !(migration.remove) {
+        validator, validates = migration.field.(Validator)
+    }
+
+    var migrate func(*node) error
+    migrate = func(node *node) error {
+        node.checkpoint(this.transaction.savepoints)
+
+        if node.leaf() {
+            var value []byte
+
+            if !(migration.remove) {
+                if node.placeholder() || (migration.value == nil) {
+                    value = migration.field.Placeholder()
+                } else {
+                    value = migration.field.Encode(migration.value(recordkeymatch(this, node)))
+                }
+            }
+
+            node.values = migration.values(node.values, value)
+
+            if validates {
+                return validator.Validate(value)
+            }
+
+            return nil
+        } else {
+            lefterror := migrate(node.children.left)
+
+            if lefterror != nil {
+                return lefterror
+            }
+
+            return migrate(node.children.right)
+        }
+    }
+
+    fields := this.fields
+    err := migrate(this.root)
+
+    if err != nil {
+        this.Rollback()
+        return err
+    }
+
+    this.fields = migration.fields(fields)
+
+    var refresh func(*node) error
+    refresh = func(node *node) error {
+        if !(node.leaf()) {
+            lefterror := refresh(node.children.left)
+
+            if lefterror != nil {
+                return lefterror
+            }
+
+            righterror := refresh(node.children.right)
+
+            if righterror != nil {
+                return righterror
+            }
+        }
+
+        return this.update(node)
+    }
+
+    err = refresh(this.root)
+
+    if err != nil {
+        this.fields = fields
+        this.Rollback()
+
+        return err
+    }
+
+    this.End()
+
+    indexes := make(map[int]*index)
+
+    for field, index := range(this.indexes) {
+        if migration.remove {
+            if field < migration.index {
+                indexes[field] = index
+            } else if field > migration.index {
+                indexes[field - 1] = index
+            }
+        } else {
+            if field < migration.index {
+                indexes[field] = index
+            } else {
+                indexes[field + 1] = index
+            }
+        }
+    }
+
+    this.indexes = indexes
+    this.reindex()
+
+    return nil
+}
+
+// Private methods (collection) (migration)
+
+func (this *collection) applymigration(migration Migration) error {
+    if this.transaction.ongoing {
+        panic("Cannot migrate a collection while a transaction is ongoing.")
+    }
+
+    for index := 0; index < len(migration.proofs); index++ {
+        if !(this.Verify(migration.proofs[index])) {
+            if this.AutoCollect.value {
+                this.Collect()
+            }
+
+            return errors.New("Invalid migration: proof invalid.")
+        }
+    }
+
+    error := this.Migrate(migration)
+
+    if (error != nil) && this.AutoCollect.value {
+        this.Collect()
+    }
+
+    return error
+}
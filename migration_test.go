@@ -0,0 +1,253 @@
+package collection
+
+import "testing"
+import "errors"
+import "encoding/binary"
+
+type TestMigrationBrokenField struct {
+    Stake64
+}
+
+func (this TestMigrationBrokenField) Parent(left []byte, right []byte) ([]byte, error) {
+    return []byte{}, errors.New("Broken field.")
+}
+
+func TestMigrationAddField(test *testing.T) {
+    ctx := testctx("[migration.go]", test)
+
+    stake64 := Stake64{}
+    data := Data{}
+
+    collection := EmptyCollection(data)
+    reference := EmptyCollection(stake64, data)
+    placeholder := EmptyCollection(data, stake64)
+
+    for index := 0; index < 512; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        collection.Add(key, key)
+        reference.Add(key, uint64(index), key)
+        placeholder.Add(key, key, uint64(0))
+    }
+
+    clone := collection.Clone()
+
+    error := collection.Migrate(AddField(0, stake64, func(record Record) interface{} {
+        return binary.BigEndian.Uint64(record.Key())
+    }))
+
+    if error != nil {
+        test.Error("[migration.go]", "[addfield]", "Migrate() yields an error on a valid migration.")
+    }
+
+    if len(collection.fields) != 2 {
+        test.Error("[migration.go]", "[addfield]", "Migrate() does not add the field provided.")
+    }
+
+    ctx.verify.tree("[addfield]", &collection)
+
+    for index := 0; index < 512; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        ctx.verify.values("[addfield]", &collection, key, uint64(index), key)
+    }
+
+    if collection.root.label != reference.root.label {
+        test.Error("[migration.go]", "[addfield]", "Migrate() produces a root label different from the reference collection.")
+    }
+
+    error = clone.Migrate(AddField(1, stake64, nil))
+
+    if error != nil {
+        test.Error("[migration.go]", "[addfield]", "Migrate() yields an error on a valid migration without default.")
+    }
+
+    if clone.root.label != placeholder.root.label {
+        test.Error("[migration.go]", "[addfield]", "Migrate() without default does not fill values with placeholders.")
+    }
+
+    ctx.should_panic("[addfield]", func() {
+        collection.Migrate(AddField(3, stake64, nil))
+    })
+}
+
+func TestMigrationRemoveField(test *testing.T) {
+    ctx := testctx("[migration.go]", test)
+
+    stake64 := Stake64{}
+    data := Data{}
+
+    collection := EmptyCollection(stake64, data)
+    reference := EmptyCollection(data)
+
+    for index := 0; index < 512; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        collection.Add(key, uint64(index), key)
+        reference.Add(key, key)
+    }
+
+    error := collection.Migrate(RemoveField(0))
+
+    if error != nil {
+        test.Error("[migration.go]", "[removefield]", "Migrate() yields an error on a valid migration.")
+    }
+
+    if len(collection.fields) != 1 {
+        test.Error("[migration.go]", "[removefield]", "Migrate() does not remove the field provided.")
+    }
+
+    ctx.verify.tree("[removefield]", &collection)
+
+    if collection.root.label != reference.root.label {
+        test.Error("[migration.go]", "[removefield]", "Migrate() produces a root label different from the reference collection.")
+    }
+
+    ctx.should_panic("[removefield]", func() {
+        collection.Migrate(RemoveField(1))
+    })
+
+    ctx.should_panic("[removefield]", func() {
+        collection.Begin()
+        collection.Migrate(RemoveField(0))
+    })
+}
+
+func TestMigrationFailure(test *testing.T) {
+    ctx := testctx("[migration.go]", test)
+
+    data := Data{}
+    collection := EmptyCollection(data)
+
+    collection.Add([]byte("mykey"), []byte("myvalue"))
+    collection.Add([]byte("myotherkey"), []byte("myothervalue"))
+
+    label := collection.root.label
+
+    error := collection.Migrate(AddField(0, TestMigrationBrokenField{}, nil))
+
+    if error == nil {
+        test.Error("[migration.go]", "[failure]", "Migrate() does not yield an error when a parent value cannot be computed.")
+    }
+
+    if len(collection.fields) != 1 {
+        test.Error("[migration.go]", "[failure]", "Failed Migrate() does not restore the original fields.")
+    }
+
+    if collection.root.label != label {
+        test.Error("[migration.go]", "[failure]", "Failed Migrate() does not restore the original tree.")
+    }
+
+    if collection.transaction.ongoing {
+        test.Error("[migration.go]", "[failure]", "Failed Migrate() leaves a transaction ongoing.")
+    }
+
+    ctx.verify.tree("[failure]", &collection)
+
+    verifier := EmptyVerifier(data)
+
+    if verifier.Migrate(RemoveField(0)) == nil {
+        test.Error("[migration.go]", "[unknown]", "Migrate() does not yield an error on a collection with unknown nodes.")
+    }
+
+    _, error = verifier.Prove(RemoveField(0))
+
+    if error == nil {
+        test.Error("[migration.go]", "[prove]", "Prove() does not yield an error on a collection with unknown nodes.")
+    }
+}
+
+func TestMigrationApply(test *testing.T) {
+    ctx := testctx("[migration.go]", test)
+
+    stake64 := Stake64{}
+    data := Data{}
+
+    collection := EmptyCollection(data)
+    verifier := EmptyVerifier(data)
+
+    migration, error := collection.Prove(AddField(1, stake64, nil))
+
+    if error != nil {
+        test.Error("[migration.go]", "[prove]", "Prove() yields an error on an empty collection.")
+    }
+
+    if len(migration.Records()) != 1 {
+        test.Error("[migration.go]", "[prove]", "Prove() does not produce a proof for an empty collection.")
+    }
+
+    if verifier.Apply(migration) != nil {
+        test.Error("[migration.go]", "[apply]", "Apply() yields an error on a valid migration on an empty collection.")
+    }
+
+    collection.Migrate(migration)
+
+    if verifier.root.label != collection.root.label {
+        test.Error("[migration.go]", "[apply]", "Apply() on an empty verifier does not produce the collection's root label.")
+    }
+
+    collection = EmptyCollection(data)
+    verifier = EmptyVerifier(data)
+
+    collection.Begin()
+
+    for index := 0; index < 64; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        collection.Add(key, key)
+    }
+
+    collection.End()
+
+    verifier.root.label = collection.root.label
+
+    migration, error = collection.Prove(AddField(0, stake64, func(record Record) interface{} {
+        return uint64(len(record.Key()))
+    }))
+
+    if error != nil {
+        test.Error("[migration.go]", "[prove]", "Prove() yields an error on a known collection.")
+    }
+
+    if len(migration.Records()) != 64 {
+        test.Error("[migration.go]", "[prove]", "Prove() does not produce one proof per record.")
+    }
+
+    if verifier.Apply(migration) != nil {
+        test.Error("[migration.go]", "[apply]", "Apply() yields an error on a valid migration.")
+    }
+
+    collection.Migrate(migration)
+
+    if verifier.root.label != collection.root.label {
+        test.Error("[migration.go]", "[apply]", "Apply() does not produce the collection's root label.")
+    }
+
+    if verifier.root.known {
+        test.Error("[migration.go]", "[apply]", "Apply() does not collect the verifier after the migration.")
+    }
+
+    ctx.verify.tree("[apply]", &collection)
+
+    migration, _ = collection.Prove(RemoveField(0))
+    migration.proofs[0].steps[0].Left.Label[0]++
+
+    if verifier.Apply(migration) == nil {
+        test.Error("[migration.go]", "[apply]", "Apply() does not yield an error on a migration with an invalid proof.")
+    }
+
+    migration, _ = collection.Prove(RemoveField(0))
+    migration.proofs[32].steps[0].Left.Label[0]++
+
+    if verifier.Apply(migration) == nil {
+        test.Error("[migration.go]", "[apply]", "Apply() does not yield an error on a migration with an invalid proof after valid ones.")
+    }
+
+    if verifier.root.known {
+        test.Error("[migration.go]", "[apply]", "Apply() keeps the proofs verified before an invalid one.")
+    }
+}
@@ -6,6 +6,8 @@ type navigator struct {
     collection *collection
     field int
     query []byte
+
+    best bool
 }
 
 // Constructors
@@ -15,12 +17,28 @@ func (this *collection) Navigate(field int, value interface{}) navigator {
         panic("Field unknown.")
     }
 
-    return navigator{this, field, this.fields[field].Encode(value)}
+    return navigator{this, field, this.fields[field].Encode(value), false}
+}
+
+func (this *collection) Best(field int) navigator {
+    if (field < 0) || (field >= len(this.fields)) {
+        panic("Field unknown.")
+    }
+
+    if _, ordered := this.fields[field].(Ordered); !ordered {
+        panic("Field is not ordered.")
+    }
+
+    return navigator{this, field, []byte{}, true}
 }
 
 // Methods
 
 func (this navigator) Record() (Record, error) {
+    if searchable, search := this.collection.fields[this.field].(Searchable); search && !(this.best) {
+        return this.search(searchable)
+    }
+
     cursor := this.collection.root
 
     for {
@@ -29,13 +47,17 @@ func (this navigator) Record() (Record, error) {
         }
 
         if cursor.leaf() {
+            if this.best && cursor.placeholder() {
+                return Record{}, errors.New("No records.")
+            }
+
             return recordquerymatch(this.collection, this.field, this.query, cursor), nil
         } else {
             if !(cursor.children.left.known) || !(cursor.children.right.known) {
                 return Record{}, errors.New("Record lies in an unknown subtree.")
             }
 
-            navigation, error := this.collection.fields[this.field].Navigate(this.query, cursor.values[this.field], cursor.children.left.values[this.field], cursor.children.right.values[this.field])
+            navigation, error := this.navigate(cursor)
             if error != nil {
                 return Record{}, error
             }
@@ -48,3 +70,90 @@ func (this navigator) Record() (Record, error) {
         }
     }
 }
+
+func (this navigator) Proof() (Proof, error) {
+    query := make([]byte, len(this.query))
+    copy(query, this.query)
+
+    record, error := navigator{this.collection, this.field, query, this.best}.Record()
+
+    if error != nil {
+        return Proof{}, error
+    }
+
+    return this.collection.Get(record.Key()).Proof()
+}
+
+// Private methods
+
+func (this navigator) navigate(cursor *node) (Navigation, error) {
+    field := this.collection.fields[this.field]
+
+    parent := cursor.values[this.field]
+    left := cursor.children.left.values[this.field]
+    right := cursor.children.right.values[this.field]
+
+    if !(this.best) {
+        return field.Navigate(this.query, parent, left, right)
+    }
+
+    if cursor.children.left.placeholder() && !(cursor.children.right.placeholder()) {
+        return Right, nil
+    }
+
+    if cursor.children.right.placeholder() && !(cursor.children.left.placeholder()) {
+        return Left, nil
+    }
+
+    before, error := field.(Ordered).Before(right, left)
+
+    if error != nil {
+        return false, error
+    }
+
+    if before {
+        return Right, nil
+    } else {
+        return Left, nil
+    }
+}
+
+func (this navigator) search(field Searchable) (Record, error) {
+    unknown := false
+
+    var explore func(*node) *node
+    explore = func(cursor *node) *node {
+        if !(cursor.known) {
+            unknown = true
+            return nil
+        }
+
+        if cursor.placeholder() || !(field.Match(this.query, cursor.values[this.field])) {
+            return nil
+        }
+
+        if cursor.leaf() {
+            return cursor
+        }
+
+        found := explore(cursor.children.left)
+
+        if found == nil {
+            found = explore(cursor.children.right)
+        }
+
+        return found
+    }
+
+    found := explore(this.collection.root)
+
+    if found != nil {
+        return recordquerymatch(this.collection, this.field, this.query, found), nil
+    }
+
+    if unknown {
+        return Record{}, errors.New("Record lies in an unknown subtree.")
+    }
+
+    return Record{}, errors.New("Tag not found.")
+}
@@ -116,3 +116,172 @@ func TestNavigatorsRecord(test *testing.T) {
         test.Error("[navigators.go]", "[record]", "Navigation does not yield an error on unknown tree.")
     }
 }
+
+func TestNavigatorsBest(test *testing.T) {
+    ctx := testctx("[navigators.go]", test)
+
+    max64 := Max64{}
+    min64 := Min64{}
+    stake64 := Stake64{}
+
+    collection := EmptyCollection(max64, min64, stake64)
+
+    for index := 0; index < 512; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        collection.Add(key, uint64((index * 3) % 512), uint64((index * 5) % 512 + 1), uint64(index))
+    }
+
+    record, error := collection.Best(0).Record()
+
+    if error != nil {
+        test.Error("[navigators.go]", "[best]", "Best navigation fails on valid tree.")
+    }
+
+    values, _ := record.Values()
+
+    if values[0].(uint64) != 511 {
+        test.Error("[navigators.go]", "[best]", "Best navigation does not yield the maximum record.")
+    }
+
+    proof, error := collection.Best(1).Proof()
+
+    if error != nil {
+        test.Error("[navigators.go]", "[proof]", "Proof() fails on valid navigation.")
+    }
+
+    values, _ = proof.Values()
+
+    if values[1].(uint64) != 1 {
+        test.Error("[navigators.go]", "[best]", "Best navigation does not yield the minimum record.")
+    }
+
+    record, error = collection.Navigate(0, uint64(300)).Record()
+    values, _ = record.Values()
+
+    if (error != nil) || (values[0].(uint64) < 300) {
+        test.Error("[navigators.go]", "[record]", "Max64 navigation does not yield a record above the query.")
+    }
+
+    _, error = collection.Navigate(0, uint64(512)).Record()
+
+    if error == nil {
+        test.Error("[navigators.go]", "[record]", "Max64 navigation does not yield an error on a query above the maximum.")
+    }
+
+    navigator := collection.Navigate(2, uint64(1000))
+    proof, _ = navigator.Proof()
+    record, _ = navigator.Record()
+
+    if !equal(proof.Key(), record.Key()) {
+        test.Error("[navigators.go]", "[proof]", "Proof() consumes the navigator query.")
+    }
+
+    ctx.should_panic("[best]", func() {
+        collection.Best(2)
+    })
+
+    ctx.should_panic("[best]", func() {
+        collection.Best(3)
+    })
+}
+
+func TestNavigatorsBestPlaceholders(test *testing.T) {
+    max64 := Max64{}
+    min64 := Min64{}
+
+    empty := EmptyCollection(max64, min64)
+
+    if _, error := empty.Best(0).Record(); error == nil {
+        test.Error("[navigators.go]", "[best]", "Best navigation does not yield an error on an empty collection.")
+    }
+
+    if _, error := empty.Best(1).Proof(); error == nil {
+        test.Error("[navigators.go]", "[best]", "Best Proof() does not yield an error on an empty collection.")
+    }
+
+    single := EmptyCollection(max64, min64)
+    single.Add([]byte("mykey"), uint64(0), ^uint64(0))
+
+    for field := 0; field < 2; field++ {
+        record, error := single.Best(field).Record()
+
+        if (error != nil) || !(record.Match()) || !(equal(record.Key(), []byte("mykey"))) {
+            test.Error("[navigators.go]", "[best]", "Best navigation yields a placeholder over a record with the same value.")
+        }
+    }
+
+    zeros := EmptyCollection(max64, min64)
+
+    for index := 0; index < 64; index++ {
+        zeros.Add([]byte{byte(index)}, uint64(0), ^uint64(0))
+    }
+
+    for field := 0; field < 2; field++ {
+        proof, error := zeros.Best(field).Proof()
+
+        if (error != nil) || !(proof.Match()) || (len(proof.Key()) == 0) {
+            test.Error("[navigators.go]", "[best]", "Best Proof() yields a placeholder on a collection of placeholder values.")
+        }
+    }
+}
+
+func TestNavigatorsBloom(test *testing.T) {
+    bloom := Bloom{}
+    collection := EmptyCollection(bloom)
+
+    for index := 0; index < 300; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        collection.Add(key, []byte("tag" + string(key)))
+    }
+
+    for index := 0; index < 300; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        record, error := collection.Navigate(0, []byte("tag" + string(key))).Record()
+
+        if (error != nil) || !(equal(record.Key(), key)) {
+            test.Error("[navigators.go]", "[bloom]", "Navigate() does not find the record holding an existing tag.")
+            break
+        }
+
+        proof, error := collection.Navigate(0, []byte("tag" + string(key))).Proof()
+
+        if (error != nil) || !(equal(proof.Key(), key)) || !(proof.Match()) {
+            test.Error("[navigators.go]", "[bloom]", "Navigate() does not prove the record holding an existing tag.")
+            break
+        }
+    }
+
+    for index := 300; index < 400; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        if _, error := collection.Navigate(0, []byte("tag" + string(key))).Record(); error == nil {
+            test.Error("[navigators.go]", "[bloom]", "Navigate() reports a missing tag as found.")
+            break
+        }
+    }
+
+    key := make([]byte, 8)
+    navigator := collection.Navigate(0, []byte("tag" + string(key)))
+
+    if _, error := navigator.navigate(collection.root); error == nil {
+        test.Error("[navigators.go]", "[bloom]", "Bloom fields can be navigated greedily.")
+    }
+
+    if record, error := navigator.Record(); (error != nil) || !(equal(record.Key(), key)) {
+        test.Error("[navigators.go]", "[bloom]", "Navigate() does not search Bloom fields.")
+    }
+
+    verifier := EmptyVerifier(bloom)
+    verifier.root.label = collection.root.label
+
+    if _, error := verifier.Navigate(0, []byte("tag")).Record(); error == nil {
+        test.Error("[navigators.go]", "[bloom]", "Navigate() does not yield an error on an unknown subtree.")
+    }
+}
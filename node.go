@@ -6,10 +6,12 @@ type node struct {
     label [csha256.Size]byte
 
     known bool
+    access uint64
 
     transaction struct {
         inconsistent bool
         backup *node
+        savepoint int
     }
 
     key []byte
@@ -44,6 +46,26 @@ func (this * node) placeholder() bool {
     return this.leaf() && (len(this.key) == 0)
 }
 
+func (this *node) committed() *node {
+    cursor := this
+
+    for cursor.transaction.backup != nil {
+        cursor = cursor.transaction.backup
+    }
+
+    return cursor
+}
+
+func (this *node) size() int {
+    size := csha256.Size + len(this.key)
+
+    for index := 0; index < len(this.values); index++ {
+        size += len(this.values[index])
+    }
+
+    return size
+}
+
 // Methods
 
 func (this *node) backup() {
@@ -52,6 +74,7 @@ func (this *node) backup() {
 
         this.transaction.backup.label = this.label
         this.transaction.backup.known = this.known
+        this.transaction.backup.access = this.access
         this.transaction.backup.transaction.inconsistent = this.transaction.inconsistent
 
         this.transaction.backup.key = this.key
@@ -64,11 +87,27 @@ func (this *node) backup() {
     }
 }
 
+func (this *node) checkpoint(savepoint int) {
+    if (this.transaction.backup != nil) && (this.transaction.savepoint < savepoint) {
+        previous := this.transaction.backup
+        previoussavepoint := this.transaction.savepoint
+
+        this.transaction.backup = nil
+        this.backup()
+
+        this.transaction.backup.transaction.backup = previous
+        this.transaction.backup.transaction.savepoint = previoussavepoint
+    } else {
+        this.backup()
+    }
+
+    this.transaction.savepoint = savepoint
+}
+
 func (this *node) restore() {
     if this.transaction.backup != nil {
         backup := this.transaction.backup
         (*this) = (*backup)
-        this.transaction.backup = nil
     }
 }
 
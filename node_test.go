@@ -99,6 +99,42 @@ func TestNodeBackupRestore(test *testing.T) {
     }
 }
 
+func TestNodeCheckpoint(test *testing.T) {
+    root := node{}
+    root.key = []byte("first")
+
+    root.checkpoint(0)
+    root.key = []byte("second")
+    root.checkpoint(0)
+
+    if (root.transaction.backup.transaction.backup != nil) || !equal(root.transaction.backup.key, []byte("first")) {
+        test.Error("[node.go]", "[checkpoint]", "checkpoint() stacks a backup on a node already backed up at the same savepoint.")
+    }
+
+    root.checkpoint(2)
+    root.key = []byte("third")
+
+    if (root.transaction.savepoint != 2) || !equal(root.transaction.backup.key, []byte("second")) {
+        test.Error("[node.go]", "[checkpoint]", "checkpoint() does not stack a backup for a new savepoint.")
+    }
+
+    if !equal(root.committed().key, []byte("first")) {
+        test.Error("[node.go]", "[committed]", "committed() does not return the oldest backup.")
+    }
+
+    root.restore()
+
+    if !equal(root.key, []byte("second")) || (root.transaction.savepoint != 0) || (root.transaction.backup == nil) {
+        test.Error("[node.go]", "[restore]", "restore() does not pop a single backup from the stack.")
+    }
+
+    root.restore()
+
+    if !equal(root.key, []byte("first")) || (root.transaction.backup != nil) {
+        test.Error("[node.go]", "[restore]", "restore() does not restore the oldest backup.")
+    }
+}
+
 func TestNodeBranchPrune(test *testing.T) {
     root := node{}
     root.branch()
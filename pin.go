@@ -0,0 +1,51 @@
+package collection
+
+import csha256 "crypto/sha256"
+
+// Methods (collection) (pin)
+
+func (this *collection) Pin(key []byte) {
+    if this.pins == nil {
+        this.pins = make(map[[csha256.Size]byte]int)
+    }
+
+    this.pins[this.path(key)]++
+}
+
+func (this *collection) Unpin(key []byte) {
+    path := this.path(key)
+
+    if this.pins[path] == 0 {
+        panic("Key not pinned.")
+    }
+
+    this.pins[path]--
+
+    if this.pins[path] == 0 {
+        delete(this.pins, path)
+    }
+}
+
+func (this *collection) Pinned(key []byte) bool {
+    return this.pins[this.path(key)] > 0
+}
+
+// Private methods (collection) (pin)
+
+func (this *collection) keep(path [csha256.Size]byte, bits int) bool {
+    if !(equal(this.Scope.salt, this.salt)) {
+        this.Scope.salted(this.salt)
+    }
+
+    if this.Scope.match(path, bits) {
+        return true
+    }
+
+    for pinned, _ := range(this.pins) {
+        if match(path[:], pinned[:], bits) {
+            return true
+        }
+    }
+
+    return false
+}
@@ -0,0 +1,98 @@
+package collection
+
+import "testing"
+
+func TestPinMethods(test *testing.T) {
+    ctx := testctx("[pin.go]", test)
+    collection := EmptyCollection()
+
+    collection.Pin([]byte("mykey"))
+    collection.Pin([]byte("mykey"))
+
+    if !(collection.Pinned([]byte("mykey"))) || collection.Pinned([]byte("myotherkey")) {
+        test.Error("[pin.go]", "[pinned]", "Pinned() does not report pinned keys.")
+    }
+
+    collection.Unpin([]byte("mykey"))
+
+    if !(collection.Pinned([]byte("mykey"))) {
+        test.Error("[pin.go]", "[unpin]", "Unpin() does not count references.")
+    }
+
+    clone := collection.Clone()
+    collection.Unpin([]byte("mykey"))
+
+    if collection.Pinned([]byte("mykey")) || (len(collection.pins) != 0) {
+        test.Error("[pin.go]", "[unpin]", "Unpin() does not release the last reference.")
+    }
+
+    if !(clone.Pinned([]byte("mykey"))) {
+        test.Error("[pin.go]", "[clone]", "Clone() does not copy pins, or shares them with the original.")
+    }
+
+    ctx.should_panic("[unpin]", func() {
+        collection.Unpin([]byte("mykey"))
+    })
+}
+
+func TestPinCollect(test *testing.T) {
+    ctx := testctx("[pin.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 16; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    verifier := EmptyVerifier(stake64)
+    verifier.root.label = collection.root.label
+
+    verifier.Pin([]byte{3})
+    verifier.Pin([]byte{3})
+
+    proof, _ := collection.Get([]byte{3}).Proof()
+    verifier.Verify(proof)
+
+    proof, _ = collection.Get([]byte{4}).Proof()
+    verifier.Verify(proof)
+
+    verifier.Collect()
+    ctx.verify.scope("[collect]", &verifier)
+
+    verifier.Begin()
+    verifier.Set([]byte{3}, uint64(30))
+    verifier.End()
+
+    collection.Set([]byte{3}, uint64(30))
+
+    record, error := verifier.Get([]byte{3}).Record()
+
+    if error != nil {
+        test.Error("[pin.go]", "[collect]", "Collect() prunes a pinned key.")
+    } else if values, _ := record.Values(); values[0].(uint64) != 30 {
+        test.Error("[pin.go]", "[collect]", "Pinned record has wrong values after a transaction.")
+    }
+
+    if _, error := verifier.Get([]byte{4}).Record(); error == nil {
+        test.Error("[pin.go]", "[collect]", "Collect() keeps a record that is neither in scope nor pinned.")
+    }
+
+    if verifier.root.label != collection.root.label {
+        test.Error("[pin.go]", "[collect]", "Pinned verifier has wrong root after a transaction.")
+    }
+
+    verifier.Unpin([]byte{3})
+    verifier.Collect()
+
+    if _, error := verifier.Get([]byte{3}).Record(); error != nil {
+        test.Error("[pin.go]", "[collect]", "Collect() prunes a key that is still pinned.")
+    }
+
+    verifier.Unpin([]byte{3})
+    verifier.Collect()
+
+    if verifier.root.known {
+        test.Error("[pin.go]", "[collect]", "Collect() does not prune a key after it is unpinned.")
+    }
+}
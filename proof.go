@@ -22,7 +22,8 @@ type dump struct {
 
 func dumpnode(node *node) (dump dump) {
     dump.Label = node.label
-    dump.Values = node.values
+    dump.Values = make([][]byte, len(node.values))
+    copy(dump.Values, node.values)
 
     if node.leaf() {
         dump.Key = node.key
@@ -55,7 +56,8 @@ func (this *dump) to(node *node) {
     if !(node.known) && (node.label == this.Label) {
         node.known = true
         node.label = this.Label
-        node.values = this.Values
+        node.values = make([][]byte, len(this.Values))
+        copy(node.values, this.Values)
 
         if this.leaf() {
             node.key = this.Key
@@ -101,7 +103,7 @@ func (this Proof) Match() bool {
         return false
     }
 
-    path := sha256(this.key)
+    path := this.collection.path(this.key)
     depth := len(this.steps) - 1
 
     if bit(path[:], depth) {
@@ -116,7 +118,7 @@ func (this Proof) Values() ([]interface{}, error) {
         return []interface{}{}, errors.New("Proof has no steps.")
     }
 
-    path := sha256(this.key)
+    path := this.collection.path(this.key)
     depth := len(this.steps) - 1
 
     match := false
@@ -169,7 +171,7 @@ func (this Proof) consistent() bool {
     }
 
     cursor := &(this.root)
-    path := sha256(this.key)
+    path := this.collection.path(this.key)
 
     for depth := 0; depth < len(this.steps); depth++ {
         if (cursor.Children.Left != this.steps[depth].Left.Label) || (cursor.Children.Right != this.steps[depth].Right.Label) {
@@ -218,5 +220,12 @@ func (this *collection) Deserialize(buffer []byte) (Proof, error) {
         return Proof{}, error
     }
 
-    return Proof{this, deserializable.Key, deserializable.Root, deserializable.Steps}, nil
+    proof := Proof{this, deserializable.Key, deserializable.Root, deserializable.Steps}
+    error = this.wellformed(proof)
+
+    if error != nil {
+        return Proof{}, error
+    }
+
+    return proof, nil
 }
@@ -0,0 +1,140 @@
+package collection
+
+import "errors"
+import "container/heap"
+
+// rankqueue
+
+type rankqueue struct {
+    field int
+    ordered Ordered
+    nodes []*node
+}
+
+// Interface
+
+func (this *rankqueue) Len() int {
+    return len(this.nodes)
+}
+
+func (this *rankqueue) Less(i int, j int) bool {
+    before, _ := this.ordered.Before(this.nodes[i].values[this.field], this.nodes[j].values[this.field])
+    return before
+}
+
+func (this *rankqueue) Swap(i int, j int) {
+    this.nodes[i], this.nodes[j] = this.nodes[j], this.nodes[i]
+}
+
+func (this *rankqueue) Push(item interface{}) {
+    this.nodes = append(this.nodes, item.(*node))
+}
+
+func (this *rankqueue) Pop() interface{} {
+    last := this.nodes[len(this.nodes) - 1]
+    this.nodes = this.nodes[:len(this.nodes) - 1]
+
+    return last
+}
+
+// ranking
+
+type ranking struct {
+    collection *collection
+    queue *rankqueue
+}
+
+// Constructors
+
+func (this *collection) Rank(field int) ranking {
+    if (field < 0) || (field >= len(this.fields)) {
+        panic("Field unknown.")
+    }
+
+    ordered, isordered := this.fields[field].(Ordered)
+
+    if !isordered {
+        panic("Field is not ordered.")
+    }
+
+    queue := &rankqueue{field, ordered, []*node{}}
+    heap.Push(queue, this.root)
+
+    return ranking{this, queue}
+}
+
+// Methods
+
+func (this ranking) Next() (Proof, error) {
+    leaf, error := this.next()
+
+    if error != nil {
+        return Proof{}, error
+    }
+
+    if leaf == nil {
+        return Proof{}, errors.New("No more records.")
+    }
+
+    return this.collection.Get(leaf.key).Proof()
+}
+
+// Private methods
+
+func (this ranking) next() (*node, error) {
+    for this.queue.Len() > 0 {
+        cursor := heap.Pop(this.queue).(*node)
+
+        if !(cursor.known) {
+            return nil, errors.New("Record lies in an unknown subtree.")
+        }
+
+        if cursor.leaf() {
+            if cursor.placeholder() {
+                continue
+            }
+
+            return cursor, nil
+        }
+
+        if !(cursor.children.left.known) || !(cursor.children.right.known) {
+            return nil, errors.New("Record lies in an unknown subtree.")
+        }
+
+        heap.Push(this.queue, cursor.children.left)
+        heap.Push(this.queue, cursor.children.right)
+    }
+
+    return nil, nil
+}
+
+// collection
+
+// Methods (collection) (ranking)
+
+func (this *collection) Top(field int, k int) ([]Proof, error) {
+    ranking := this.Rank(field)
+    proofs := []Proof{}
+
+    for index := 0; index < k; index++ {
+        leaf, error := ranking.next()
+
+        if error != nil {
+            return []Proof{}, error
+        }
+
+        if leaf == nil {
+            break
+        }
+
+        proof, error := this.Get(leaf.key).Proof()
+
+        if error != nil {
+            return []Proof{}, error
+        }
+
+        proofs = append(proofs, proof)
+    }
+
+    return proofs, nil
+}
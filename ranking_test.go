@@ -0,0 +1,110 @@
+package collection
+
+import "testing"
+import "encoding/binary"
+
+func TestRankingNext(test *testing.T) {
+    ctx := testctx("[ranking.go]", test)
+
+    max64 := Max64{}
+    data := Data{}
+
+    collection := EmptyCollection(max64, data)
+
+    for index := 0; index < 512; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        collection.Add(key, uint64((index * 7) % 512), key)
+    }
+
+    ranking := collection.Rank(0)
+
+    for expected := 511; expected >= 0; expected-- {
+        proof, error := ranking.Next()
+
+        if error != nil {
+            test.Error("[ranking.go]", "[next]", "Next() yields an error before all records are ranked.")
+            return
+        }
+
+        values, _ := proof.Values()
+
+        if values[0].(uint64) != uint64(expected) {
+            test.Error("[ranking.go]", "[next]", "Next() does not yield records in decreasing order.")
+        }
+    }
+
+    _, error := ranking.Next()
+
+    if error == nil {
+        test.Error("[ranking.go]", "[next]", "Next() does not yield an error when all records are ranked.")
+    }
+
+    ctx.should_panic("[rank]", func() {
+        collection.Rank(1)
+    })
+
+    ctx.should_panic("[rank]", func() {
+        collection.Rank(2)
+    })
+}
+
+func TestRankingTop(test *testing.T) {
+    max64 := Max64{}
+    min64 := Min64{}
+
+    collection := EmptyCollection(max64, min64)
+
+    for index := 0; index < 128; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        collection.Add(key, uint64(index), uint64(index))
+    }
+
+    verifier := EmptyVerifier(max64, min64)
+    verifier.root.label = collection.root.label
+
+    proofs, error := collection.Top(0, 3)
+
+    if (error != nil) || (len(proofs) != 3) {
+        test.Error("[ranking.go]", "[top]", "Top() does not yield the number of records requested.")
+    }
+
+    for index, proof := range(proofs) {
+        values, _ := proof.Values()
+
+        if values[0].(uint64) != uint64(127 - index) {
+            test.Error("[ranking.go]", "[top]", "Top() yields wrong records on maximum field.")
+        }
+
+        if !(verifier.Verify(proof)) {
+            test.Error("[ranking.go]", "[top]", "Top() yields invalid proofs.")
+        }
+    }
+
+    proofs, _ = collection.Top(1, 2)
+
+    for index, proof := range(proofs) {
+        values, _ := proof.Values()
+
+        if values[1].(uint64) != uint64(index) {
+            test.Error("[ranking.go]", "[top]", "Top() yields wrong records on minimum field.")
+        }
+    }
+
+    proofs, error = collection.Top(0, 200)
+
+    if (error != nil) || (len(proofs) != 128) {
+        test.Error("[ranking.go]", "[top]", "Top() does not yield all records when less than requested are available.")
+    }
+
+    collection.root.children.left.known = false
+
+    _, error = collection.Top(0, 200)
+
+    if error == nil {
+        test.Error("[ranking.go]", "[top]", "Top() does not yield an error on unknown subtree.")
+    }
+}
@@ -1,51 +1,296 @@
 package collection
 
+import "errors"
 import csha256 "crypto/sha256"
+import "github.com/dedis/protobuf"
 
-// mask
+// Mask
 
-type mask struct {
-    value []byte
-    bits int
+type Mask struct {
+    Value []byte
+    Bits int
 }
 
 // Private methods
 
-func (this *mask) match(path [csha256.Size]byte, bits int) bool {
-    if bits < this.bits {
-        return match(path[:], this.value, bits)
+func (this *Mask) match(path [csha256.Size]byte, bits int) bool {
+    if bits < this.Bits {
+        return match(path[:], this.Value, bits)
     } else {
-        return match(path[:], this.value, this.bits)
+        return match(path[:], this.Value, this.Bits)
     }
 }
 
-// scope
+func (this *Mask) equal(other Mask) bool {
+    return (this.Bits == other.Bits) && match(this.Value, other.Value, this.Bits)
+}
+
+// Scope
 
-type scope struct {
-    masks []mask
+type Scope struct {
+    masks []Mask
     all bool
+
+    keys [][]byte
+    paths [][csha256.Size]byte
+
+    salt []byte
+}
+
+// Getters
+
+func (this *Scope) Masks() []Mask {
+    masks := make([]Mask, len(this.masks))
+    copy(masks, this.masks)
+
+    return masks
+}
+
+func (this *Scope) Keys() [][]byte {
+    keys := make([][]byte, len(this.keys))
+    copy(keys, this.keys)
+
+    return keys
 }
 
 // Methods
 
-func (this *scope) All() {
+func (this *Scope) All() {
     this.all = true
-    this.masks = []mask{}
+    this.masks = []Mask{}
+    this.keys = [][]byte{}
+    this.paths = [][csha256.Size]byte{}
 }
 
-func (this *scope) None() {
+func (this *Scope) None() {
     this.all = false
-    this.masks = []mask{}
+    this.masks = []Mask{}
+    this.keys = [][]byte{}
+    this.paths = [][csha256.Size]byte{}
+}
+
+func (this *Scope) Add(value []byte, bits int) {
+    this.masks = append(this.masks, Mask{value, bits})
+}
+
+func (this *Scope) Remove(value []byte, bits int) {
+    target := Mask{value, bits}
+    masks := []Mask{}
+
+    for index := 0; index < len(this.masks); index++ {
+        if !(this.masks[index].equal(target)) {
+            masks = append(masks, this.masks[index])
+        }
+    }
+
+    this.masks = masks
+}
+
+func (this *Scope) AddKey(key []byte) {
+    for index := 0; index < len(this.keys); index++ {
+        if equal(this.keys[index], key) {
+            return
+        }
+    }
+
+    this.keys = append(this.keys, key)
+    this.paths = append(this.paths, this.path(key))
 }
 
-func (this *scope) Add(value []byte, bits int) {
-    this.masks = append(this.masks, mask{value, bits})
+func (this *Scope) RemoveKey(key []byte) {
+    keys := [][]byte{}
+    paths := [][csha256.Size]byte{}
+
+    for index := 0; index < len(this.keys); index++ {
+        if !(equal(this.keys[index], key)) {
+            keys = append(keys, this.keys[index])
+            paths = append(paths, this.paths[index])
+        }
+    }
+
+    this.keys = keys
+    this.paths = paths
+}
+
+func (this *Scope) Contains(key []byte) bool {
+    return this.match(this.path(key), 8 * csha256.Size)
+}
+
+func (this *Scope) Equal(other Scope) bool {
+    lho := this.canonical()
+    rho := other.canonical()
+
+    if len(lho) != len(rho) {
+        return false
+    }
+
+    for _, mask := range(lho) {
+        found := false
+
+        for index := 0; index < len(rho); index++ {
+            if mask.equal(rho[index]) {
+                found = true
+                break
+            }
+        }
+
+        if !found {
+            return false
+        }
+    }
+
+    return true
+}
+
+func (this *Scope) Union(other Scope) (scope Scope) {
+    if this.everything() || other.everything() {
+        scope.All()
+        scope.salt = this.salt
+        return
+    }
+
+    if this.nothing() {
+        scope = other.clone()
+        scope.salted(this.salt)
+        return
+    }
+
+    if other.nothing() {
+        return this.clone()
+    }
+
+    scope.None()
+    scope.salt = this.salt
+
+    for _, operand := range([]*Scope{this, &other}) {
+        for _, mask := range(operand.masks) {
+            scope.Add(mask.Value, mask.Bits)
+        }
+
+        for _, key := range(operand.keys) {
+            scope.AddKey(key)
+        }
+    }
+
+    return
+}
+
+func (this *Scope) Intersection(other Scope) (scope Scope) {
+    scope.None()
+    scope.salt = this.salt
+
+    if this.nothing() || other.nothing() {
+        return
+    }
+
+    if this.everything() {
+        scope = other.clone()
+        scope.salted(this.salt)
+        return
+    }
+
+    if other.everything() {
+        return this.clone()
+    }
+
+    for _, lho := range(this.masks) {
+        for _, rho := range(other.masks) {
+            if lho.Bits >= rho.Bits {
+                if match(lho.Value, rho.Value, rho.Bits) {
+                    scope.Add(lho.Value, lho.Bits)
+                }
+            } else if match(lho.Value, rho.Value, lho.Bits) {
+                scope.Add(rho.Value, rho.Bits)
+            }
+        }
+    }
+
+    for _, key := range(this.keys) {
+        if other.Contains(key) {
+            scope.AddKey(key)
+        }
+    }
+
+    for _, key := range(other.keys) {
+        if this.Contains(key) {
+            scope.AddKey(key)
+        }
+    }
+
+    return
+}
+
+// Methods (serialization)
+
+func (this *Scope) Serialize() []byte {
+    serializable := struct {
+        All bool
+        Masks []Mask
+        Keys [][]byte
+    }{this.all, this.masks, this.keys}
+
+    buffer, _ := protobuf.Encode(&serializable)
+    return buffer
+}
+
+func (this *Scope) Deserialize(buffer []byte) error {
+    deserializable := struct {
+        All bool
+        Masks []Mask
+        Keys [][]byte
+    }{}
+
+    error := protobuf.Decode(buffer, &deserializable)
+
+    if error != nil {
+        return error
+    }
+
+    for _, mask := range(deserializable.Masks) {
+        if (mask.Bits < 0) || (mask.Bits > 8 * len(mask.Value)) || (mask.Bits > 8 * csha256.Size) {
+            return errors.New("Malformed mask.")
+        }
+    }
+
+    this.None()
+    this.all = deserializable.All
+
+    for _, mask := range(deserializable.Masks) {
+        this.Add(mask.Value, mask.Bits)
+    }
+
+    for _, key := range(deserializable.Keys) {
+        this.AddKey(key)
+    }
+
+    return nil
 }
 
 // Private methods
 
-func (this *scope) match(path [csha256.Size]byte, bits int) bool {
-    if len(this.masks) == 0 {
+func (this *Scope) path(key []byte) [csha256.Size]byte {
+    if len(this.salt) == 0 {
+        return sha256(key)
+    }
+
+    return sha256(this.salt, key)
+}
+
+func (this *Scope) salted(salt []byte) {
+    this.salt = make([]byte, len(salt))
+    copy(this.salt, salt)
+
+    for index := 0; index < len(this.keys); index++ {
+        this.paths[index] = this.path(this.keys[index])
+    }
+}
+
+func (this *Scope) match(path [csha256.Size]byte, bits int) bool {
+    return this.saltedmatch(path, bits, this.salt)
+}
+
+func (this *Scope) saltedmatch(path [csha256.Size]byte, bits int, salt []byte) bool {
+    if (len(this.masks) == 0) && (len(this.keys) == 0) {
         return this.all
     }
 
@@ -55,13 +300,130 @@ func (this *scope) match(path [csha256.Size]byte, bits int) bool {
         }
     }
 
+    for index := 0; index < len(this.paths); index++ {
+        keypath := this.paths[index]
+
+        if !(equal(salt, this.salt)) {
+            if len(salt) == 0 {
+                keypath = sha256(this.keys[index])
+            } else {
+                keypath = sha256(salt, this.keys[index])
+            }
+        }
+
+        if bits < 8 * csha256.Size {
+            if match(path[:], keypath[:], bits) {
+                return true
+            }
+        } else if path == keypath {
+            return true
+        }
+    }
+
     return false
 }
 
-func (this *scope) clone() (scope scope) {
-    scope.masks = make([]mask, len(this.masks))
+func (this *Scope) everything() bool {
+    return this.all && (len(this.masks) == 0) && (len(this.keys) == 0)
+}
+
+func (this *Scope) nothing() bool {
+    return !(this.all) && (len(this.masks) == 0) && (len(this.keys) == 0)
+}
+
+func (this *Scope) canonical() []Mask {
+    if this.everything() {
+        return []Mask{{make([]byte, csha256.Size), 0}}
+    }
+
+    masks := []Mask{}
+
+    for _, mask := range(this.masks) {
+        bits := mask.Bits
+
+        if bits > 8 * csha256.Size {
+            bits = 8 * csha256.Size
+        }
+
+        value := make([]byte, csha256.Size)
+
+        for index := 0; index < bits; index++ {
+            setbit(value, index, bit(mask.Value, index))
+        }
+
+        masks = append(masks, Mask{value, bits})
+    }
+
+    for _, path := range(this.paths) {
+        value := make([]byte, csha256.Size)
+        copy(value, path[:])
+
+        masks = append(masks, Mask{value, 8 * csha256.Size})
+    }
+
+    for merged := true; merged; {
+        merged = false
+        reduced := []Mask{}
+
+        for index, mask := range(masks) {
+            subsumed := false
+
+            for other := 0; other < len(masks); other++ {
+                if (other != index) && (masks[other].Bits <= mask.Bits) && match(mask.Value, masks[other].Value, masks[other].Bits) && ((masks[other].Bits < mask.Bits) || (other < index)) {
+                    subsumed = true
+                    break
+                }
+            }
+
+            if !subsumed {
+                reduced = append(reduced, mask)
+            }
+        }
+
+        masks = reduced
+
+        for lho := 0; (lho < len(masks)) && !merged; lho++ {
+            for rho := lho + 1; rho < len(masks); rho++ {
+                bits := masks[lho].Bits
+
+                if (bits > 0) && (masks[rho].Bits == bits) && match(masks[lho].Value, masks[rho].Value, bits - 1) {
+                    value := make([]byte, csha256.Size)
+                    copy(value, masks[lho].Value)
+                    setbit(value, bits - 1, false)
+
+                    siblings := []Mask{}
+
+                    for index := 0; index < len(masks); index++ {
+                        if (index != lho) && (index != rho) {
+                            siblings = append(siblings, masks[index])
+                        }
+                    }
+
+                    masks = append(siblings, Mask{value, bits - 1})
+                    merged = true
+
+                    break
+                }
+            }
+        }
+    }
+
+    return masks
+}
+
+func (this *Scope) clone() (scope Scope) {
+    scope.masks = make([]Mask, len(this.masks))
     copy(scope.masks, this.masks)
+
     scope.all = this.all
 
+    scope.keys = make([][]byte, len(this.keys))
+    copy(scope.keys, this.keys)
+
+    scope.paths = make([][csha256.Size]byte, len(this.paths))
+    copy(scope.paths, this.paths)
+
+    scope.salt = this.salt
+
     return
 }
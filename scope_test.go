@@ -2,6 +2,7 @@ package collection
 
 import "testing"
 import "encoding/hex"
+import csha256 "crypto/sha256"
 
 func TestScopeMask(test *testing.T) {
     type round struct {
@@ -30,7 +31,7 @@ func TestScopeMask(test *testing.T) {
 
     for _, round := range(rounds) {
         maskvalue, _ := hex.DecodeString(round.mask)
-        mask := mask{maskvalue, round.bits}
+        mask := Mask{maskvalue, round.bits}
 
         pathslice, _ := hex.DecodeString(round.path)
         path := digest(pathslice)
@@ -46,7 +47,7 @@ func TestScopeMask(test *testing.T) {
 }
 
 func TestScopeMethods(test *testing.T) {
-    scope := scope{}
+    scope := Scope{}
 
     value, _ := hex.DecodeString("1234567890")
     scope.Add(value, 3)
@@ -55,7 +56,7 @@ func TestScopeMethods(test *testing.T) {
         test.Error("[scope.go]", "[add]", "Add does not add to masks.")
     }
 
-    if !match(scope.masks[0].value, value, 24) || (scope.masks[0].bits != 3) {
+    if !match(scope.masks[0].Value, value, 24) || (scope.masks[0].Bits != 3) {
         test.Error("[scope.go]", "[add]", "Add adds wrong mask.")
     }
 
@@ -66,7 +67,7 @@ func TestScopeMethods(test *testing.T) {
         test.Error("[scope.go]", "[add]", "Add does not add to masks.")
     }
 
-    if !match(scope.masks[1].value, value, 40) || (scope.masks[1].bits != 40) {
+    if !match(scope.masks[1].Value, value, 40) || (scope.masks[1].Bits != 40) {
         test.Error("[scope.go]", "[add]", "Add adds wrong mask.")
     }
 
@@ -93,7 +94,7 @@ func TestScopeMethods(test *testing.T) {
 }
 
 func TestScopeMatch(test *testing.T) {
-    scope := scope{}
+    scope := Scope{}
 
     pathslice, _ := hex.DecodeString("85f46bd1ba18d1014b1179edd451ece95296e4a8c765ba8bba86c16893906398")
     path := digest(pathslice)
@@ -134,7 +135,7 @@ func TestScopeMatch(test *testing.T) {
 }
 
 func TestScopeClone(test *testing.T) {
-    scope := scope{}
+    scope := Scope{}
     scope.All()
 
     path, _ := hex.DecodeString("fa91")
@@ -157,12 +158,395 @@ func TestScopeClone(test *testing.T) {
     }
 
     for index := 0; index < len(clone.masks); index++ {
-        if clone.masks[index].bits != scope.masks[index].bits {
+        if clone.masks[index].Bits != scope.masks[index].Bits {
             test.Error("[scope.go]", "[clone]", "clone() does not properly copy the number of bits in a mask.")
         }
 
-        if !equal(clone.masks[index].value, scope.masks[index].value) {
+        if !equal(clone.masks[index].Value, scope.masks[index].Value) {
             test.Error("[scope.go]", "[clone]", "clone() does not properly copy the mask value.")
         }
     }
 }
+
+func TestScopeRemove(test *testing.T) {
+    scope := Scope{}
+    scope.None()
+
+    first, _ := hex.DecodeString("fa91")
+    second, _ := hex.DecodeString("1234")
+
+    scope.Add(first, 12)
+    scope.Add(second, 16)
+    scope.Add(first, 12)
+
+    masks := scope.Masks()
+
+    if (len(masks) != 3) || !equal(masks[1].Value, second) || (masks[1].Bits != 16) {
+        test.Error("[scope.go]", "[masks]", "Masks() does not list the masks added.")
+    }
+
+    masks[0].Bits = 3
+
+    if scope.masks[0].Bits != 12 {
+        test.Error("[scope.go]", "[masks]", "Masks() does not return a copy of the masks.")
+    }
+
+    other, _ := hex.DecodeString("fa9f")
+    scope.Remove(other, 12)
+
+    if len(scope.masks) != 1 {
+        test.Error("[scope.go]", "[remove]", "Remove() does not remove every mask matching on the bits provided.")
+    }
+
+    scope.Remove(second, 15)
+
+    if len(scope.masks) != 1 {
+        test.Error("[scope.go]", "[remove]", "Remove() removes a mask with a different number of bits.")
+    }
+
+    scope.Remove(second, 16)
+
+    if len(scope.masks) != 0 {
+        test.Error("[scope.go]", "[remove]", "Remove() does not remove a matching mask.")
+    }
+
+    pathslice, _ := hex.DecodeString("1234d1ba18d1014b1179edd451ece95296e4a8c765ba8bba86c16893906398")
+    path := digest(append(pathslice, 0))
+
+    if scope.match(path, 256) {
+        test.Error("[scope.go]", "[remove]", "Scope match succeeds after removing every mask of a None() scope.")
+    }
+}
+
+func TestScopeKeys(test *testing.T) {
+    scope := Scope{}
+    scope.None()
+
+    scope.AddKey([]byte("mykey"))
+    scope.AddKey([]byte("myotherkey"))
+    scope.AddKey([]byte("mykey"))
+
+    keys := scope.Keys()
+
+    if (len(keys) != 2) || !equal(keys[0], []byte("mykey")) || !equal(keys[1], []byte("myotherkey")) {
+        test.Error("[scope.go]", "[keys]", "Keys() does not list the keys added without duplicates.")
+    }
+
+    if !(scope.Contains([]byte("mykey"))) || !(scope.Contains([]byte("myotherkey"))) {
+        test.Error("[scope.go]", "[contains]", "Contains() returns false on a key in scope.")
+    }
+
+    if scope.Contains([]byte("somekey")) {
+        test.Error("[scope.go]", "[contains]", "Contains() returns true on a key not in scope.")
+    }
+
+    path := sha256([]byte("mykey"))
+    path[31] ^= 1
+
+    if scope.match(path, 256) {
+        test.Error("[scope.go]", "[match]", "Scope match succeeds on a path differing from every key.")
+    }
+
+    if !(scope.match(path, 255)) {
+        test.Error("[scope.go]", "[match]", "Scope match fails on a prefix of a key path.")
+    }
+
+    scope.RemoveKey([]byte("mykey"))
+
+    if scope.Contains([]byte("mykey")) || !(scope.Contains([]byte("myotherkey"))) {
+        test.Error("[scope.go]", "[removekey]", "RemoveKey() does not remove the key provided.")
+    }
+
+    clone := scope.clone()
+
+    if (len(clone.keys) != 1) || !(clone.Contains([]byte("myotherkey"))) {
+        test.Error("[scope.go]", "[clone]", "clone() does not copy keys.")
+    }
+
+    scope.All()
+
+    if (len(scope.keys) != 0) || (len(scope.paths) != 0) {
+        test.Error("[scope.go]", "[all]", "All does not wipe keys.")
+    }
+}
+
+func TestScopeCollect(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 64; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    collection.Scope.None()
+    collection.Scope.AddKey([]byte{3})
+    collection.Scope.AddKey([]byte{7})
+    collection.Collect()
+
+    for index := 0; index < 64; index++ {
+        _, error := collection.Get([]byte{byte(index)}).Record()
+
+        if (index == 3 || index == 7) && (error != nil) {
+            test.Error("[scope.go]", "[collect]", "Collect() prunes a key in scope.")
+        }
+    }
+
+    collection.Scope.RemoveKey([]byte{3})
+    collection.Collect()
+
+    if _, error := collection.Get([]byte{3}).Record(); error == nil {
+        test.Error("[scope.go]", "[collect]", "Collect() does not prune a key removed from scope.")
+    }
+
+    if _, error := collection.Get([]byte{7}).Record(); error != nil {
+        test.Error("[scope.go]", "[collect]", "Collect() prunes a key still in scope.")
+    }
+
+    collection.Scope.RemoveKey([]byte{7})
+    collection.Collect()
+
+    if collection.root.known {
+        test.Error("[scope.go]", "[collect]", "Collect() does not prune a None() scope after removing every key.")
+    }
+}
+
+func TestScopeSalted(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+    collection.Salt([]byte("mysalt"))
+
+    path := collection.path([]byte("mykey"))
+
+    collection.Scope.None()
+    collection.Scope.Add(path[:], 8 * csha256.Size)
+
+    if !(collection.Scope.Contains([]byte("mykey"))) {
+        test.Error("[scope.go]", "[contains]", "Contains() does not derive the key path from the collection salt.")
+    }
+
+    unsalted := sha256([]byte("mykey"))
+
+    collection.Scope.None()
+    collection.Scope.Add(unsalted[:], 8 * csha256.Size)
+
+    if collection.Scope.Contains([]byte("mykey")) {
+        test.Error("[scope.go]", "[contains]", "Contains() matches the unsalted key path on a salted collection.")
+    }
+
+    collection.Scope.None()
+    collection.Scope.Add(path[:], 8)
+
+    keys := Scope{}
+    keys.None()
+    keys.AddKey([]byte("mykey"))
+
+    intersection := collection.Scope.Intersection(keys)
+
+    if !(intersection.Contains([]byte("mykey"))) || !(intersection.match(path, 8 * csha256.Size)) {
+        test.Error("[scope.go]", "[intersection]", "Intersection() does not derive key paths from the collection salt.")
+    }
+
+    collection.Scope.All()
+
+    for index := 0; index < 32; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    assigned := Scope{}
+    assigned.None()
+    assigned.AddKey([]byte{3})
+
+    collection.Scope = assigned
+
+    if !(collection.InScope([]byte{3})) || collection.InScope([]byte{4}) {
+        test.Error("[scope.go]", "[assign]", "InScope() does not derive key paths from the collection salt after assigning a scope.")
+    }
+
+    collection.Collect()
+
+    if _, error := collection.Get([]byte{3}).Record(); error != nil {
+        test.Error("[scope.go]", "[assign]", "Collect() prunes a key in an assigned scope on a salted collection.")
+    }
+
+    if _, error := collection.Get([]byte{4}).Record(); error == nil {
+        test.Error("[scope.go]", "[assign]", "Collect() keeps a key outside an assigned scope on a salted collection.")
+    }
+
+    if !(equal(collection.Scope.salt, collection.salt)) || !(collection.Scope.Contains([]byte{3})) {
+        test.Error("[scope.go]", "[assign]", "Collect() does not salt an assigned scope.")
+    }
+}
+
+func TestScopeSerialization(test *testing.T) {
+    scope := Scope{}
+    scope.None()
+
+    value, _ := hex.DecodeString("fa91")
+    scope.Add(value, 12)
+    scope.AddKey([]byte("mykey"))
+
+    restored := Scope{}
+    restored.All()
+
+    error := restored.Deserialize(scope.Serialize())
+
+    if error != nil {
+        test.Error("[scope.go]", "[deserialize]", "Deserialize() yields an error on a valid serialization.")
+    }
+
+    if restored.all || (len(restored.masks) != 1) || (restored.masks[0].Bits != 12) || !equal(restored.masks[0].Value, value) {
+        test.Error("[scope.go]", "[deserialize]", "Deserialize() does not restore the masks of the scope.")
+    }
+
+    if !(restored.Contains([]byte("mykey"))) || (len(restored.keys) != 1) {
+        test.Error("[scope.go]", "[deserialize]", "Deserialize() does not restore the keys of the scope.")
+    }
+
+    scope.All()
+    restored.Deserialize(scope.Serialize())
+
+    if !(restored.all) || (len(restored.masks) != 0) || (len(restored.keys) != 0) {
+        test.Error("[scope.go]", "[deserialize]", "Deserialize() does not restore an All() scope.")
+    }
+
+    scope.None()
+    scope.Add(value, 17)
+
+    if restored.Deserialize(scope.Serialize()) == nil {
+        test.Error("[scope.go]", "[deserialize]", "Deserialize() accepts a mask with more bits than its value.")
+    }
+
+    if restored.Deserialize([]byte("definitely not a scope")) == nil {
+        test.Error("[scope.go]", "[deserialize]", "Deserialize() accepts a malformed buffer.")
+    }
+}
+
+func TestScopeEqual(test *testing.T) {
+    lho := Scope{}
+    rho := Scope{}
+
+    lho.All()
+    rho.None()
+    rho.Add([]byte{}, 0)
+
+    if !(lho.Equal(rho)) {
+        test.Error("[scope.go]", "[equal]", "Equal() distinguishes All() from a zero-bit mask.")
+    }
+
+    lho.None()
+    rho.None()
+
+    if !(lho.Equal(rho)) {
+        test.Error("[scope.go]", "[equal]", "Equal() distinguishes two None() scopes.")
+    }
+
+    lho.Add([]byte{0x80}, 1)
+    lho.Add([]byte{0x00}, 1)
+
+    rho.All()
+
+    if !(lho.Equal(rho)) {
+        test.Error("[scope.go]", "[equal]", "Equal() does not merge sibling masks.")
+    }
+
+    lho.None()
+    lho.Add([]byte{0xf0}, 4)
+    lho.Add([]byte{0xf8}, 5)
+    lho.Add([]byte{0xf3}, 4)
+
+    rho.None()
+    rho.Add([]byte{0xff}, 4)
+
+    if !(lho.Equal(rho)) {
+        test.Error("[scope.go]", "[equal]", "Equal() does not ignore subsumed masks and trailing bits.")
+    }
+
+    rho.Add([]byte{0x0f}, 4)
+
+    if lho.Equal(rho) || rho.Equal(lho) {
+        test.Error("[scope.go]", "[equal]", "Equal() succeeds on different scopes.")
+    }
+
+    path := sha256([]byte("mykey"))
+
+    lho.None()
+    lho.AddKey([]byte("mykey"))
+
+    rho.None()
+    rho.Add(path[:], 256)
+
+    if !(lho.Equal(rho)) {
+        test.Error("[scope.go]", "[equal]", "Equal() distinguishes a key from the mask of its path.")
+    }
+
+    rho.Add(path[:], 8)
+
+    if lho.Equal(rho) {
+        test.Error("[scope.go]", "[equal]", "Equal() succeeds on a scope strictly larger than a key.")
+    }
+}
+
+func TestScopeUnionIntersection(test *testing.T) {
+    all := Scope{}
+    all.All()
+
+    none := Scope{}
+    none.None()
+
+    left := Scope{}
+    left.None()
+    left.Add([]byte{0x00}, 1)
+    left.AddKey([]byte("mykey"))
+
+    right := Scope{}
+    right.None()
+    right.Add([]byte{0x80}, 1)
+
+    union := left.Union(right)
+
+    if !(union.Equal(all)) {
+        test.Error("[scope.go]", "[union]", "Union() of two complementary scopes is not All().")
+    }
+
+    if union := left.Union(none); !(union.Equal(left)) {
+        test.Error("[scope.go]", "[union]", "Union() with None() does not yield the original scope.")
+    }
+
+    if union := left.Union(all); !(union.Equal(all)) {
+        test.Error("[scope.go]", "[union]", "Union() with All() does not yield All().")
+    }
+
+    if intersection := left.Intersection(all); !(intersection.Equal(left)) {
+        test.Error("[scope.go]", "[intersection]", "Intersection() with All() does not yield the original scope.")
+    }
+
+    if intersection := left.Intersection(none); !(intersection.Equal(none)) {
+        test.Error("[scope.go]", "[intersection]", "Intersection() with None() does not yield None().")
+    }
+
+    narrow := Scope{}
+    narrow.None()
+    narrow.Add([]byte{0x40}, 2)
+    narrow.Add([]byte{0xc0}, 2)
+
+    intersection := left.Intersection(narrow)
+
+    expected := Scope{}
+    expected.None()
+    expected.Add([]byte{0x40}, 2)
+
+    if !(intersection.Equal(expected)) {
+        test.Error("[scope.go]", "[intersection]", "Intersection() does not intersect masks.")
+    }
+
+    keys := Scope{}
+    keys.None()
+    keys.AddKey([]byte("mykey"))
+    keys.AddKey([]byte("myotherkey"))
+
+    intersection = left.Intersection(keys)
+
+    if !(intersection.Contains([]byte("mykey"))) || (intersection.Contains([]byte("myotherkey")) != left.Contains([]byte("myotherkey"))) {
+        test.Error("[scope.go]", "[intersection]", "Intersection() does not intersect keys.")
+    }
+}
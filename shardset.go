@@ -0,0 +1,348 @@
+package collection
+
+import "errors"
+import csha256 "crypto/sha256"
+
+// shardsummary
+
+type shardsummary struct {
+    empty bool
+    leaf bool
+
+    label [csha256.Size]byte
+    values [][]byte
+}
+
+// ShardSet
+
+type ShardSet struct {
+    fields []Field
+    bits int
+    salt []byte
+
+    shards []collection
+    owners []int
+}
+
+// Constructors
+
+func EmptyShardSet(shards int, bits int, fields... Field) (set ShardSet) {
+    if shards < 1 {
+        panic("A shard set needs at least one shard.")
+    }
+
+    if (bits < 1) || (bits > 16) {
+        panic("Prefix length out of range.")
+    }
+
+    set.fields = fields
+    set.bits = bits
+
+    set.shards = make([]collection, shards)
+    for index := 0; index < shards; index++ {
+        set.shards[index] = EmptyCollection(fields...)
+    }
+
+    set.owners = make([]int, 1 << uint(bits))
+    for prefix := 0; prefix < len(set.owners); prefix++ {
+        set.owners[prefix] = prefix % shards
+    }
+
+    for index := 0; index < shards; index++ {
+        set.rescope(index)
+    }
+
+    return
+}
+
+// Getters
+
+func (this *ShardSet) Shard(index int) *collection {
+    return &(this.shards[index])
+}
+
+func (this *ShardSet) Owner(key []byte) int {
+    return this.owners[this.prefix(this.path(key))]
+}
+
+// Methods
+
+func (this *ShardSet) Get(key []byte) (Record, error) {
+    return this.Shard(this.Owner(key)).Get(key).Record()
+}
+
+func (this *ShardSet) Add(key []byte, values... interface{}) error {
+    return this.Shard(this.Owner(key)).Add(key, values...)
+}
+
+func (this *ShardSet) Set(key []byte, values... interface{}) error {
+    return this.Shard(this.Owner(key)).Set(key, values...)
+}
+
+func (this *ShardSet) SetField(key []byte, field int, value interface{}) error {
+    return this.Shard(this.Owner(key)).SetField(key, field, value)
+}
+
+func (this *ShardSet) Remove(key []byte) error {
+    return this.Shard(this.Owner(key)).Remove(key)
+}
+
+func (this *ShardSet) Root() ([csha256.Size]byte, error) {
+    left, lefterror := this.summary(0, 1)
+
+    if lefterror != nil {
+        return [csha256.Size]byte{}, lefterror
+    }
+
+    right, righterror := this.summary(1, 1)
+
+    if righterror != nil {
+        return [csha256.Size]byte{}, righterror
+    }
+
+    root, rooterror := this.combine(left, right, true)
+
+    if rooterror != nil {
+        return [csha256.Size]byte{}, rooterror
+    }
+
+    return root.label, nil
+}
+
+func (this *ShardSet) Salt(salt []byte) {
+    for index := 0; index < len(this.shards); index++ {
+        this.Shard(index).Salt(salt)
+    }
+
+    this.salt = make([]byte, len(salt))
+    copy(this.salt, salt)
+}
+
+func (this *ShardSet) Move(prefix int, shard int) error {
+    if (prefix < 0) || (prefix >= len(this.owners)) {
+        panic("Prefix out of range.")
+    }
+
+    if (shard < 0) || (shard >= len(this.shards)) {
+        panic("Shard unknown.")
+    }
+
+    from := this.owners[prefix]
+
+    if from == shard {
+        return nil
+    }
+
+    source := this.Shard(from)
+    destination := this.Shard(shard)
+
+    var keys [][]byte
+    var values [][][]byte
+
+    var explore func(*node) error
+    explore = func(node *node) error {
+        if !(node.known) {
+            return errors.New("Moving unknown subtree. Proof needed.")
+        }
+
+        if node.leaf() {
+            if !(node.placeholder()) && (this.prefix(this.path(node.key)) == prefix) {
+                keys = append(keys, node.key)
+                values = append(values, node.values)
+            }
+
+            return nil
+        }
+
+        lefterror := explore(node.children.left)
+
+        if lefterror != nil {
+            return lefterror
+        }
+
+        return explore(node.children.right)
+    }
+
+    error := explore(source.root)
+
+    if error != nil {
+        return error
+    }
+
+    this.owners[prefix] = shard
+    this.rescope(shard)
+
+    source.Begin()
+    destination.Begin()
+
+    for index := 0; index < len(keys); index++ {
+        removeerror := source.Remove(keys[index])
+
+        if removeerror != nil {
+            error = removeerror
+            break
+        }
+
+        adderror := destination.add(keys[index], values[index])
+
+        if adderror != nil {
+            error = adderror
+            break
+        }
+    }
+
+    if error != nil {
+        source.Rollback()
+        destination.Rollback()
+
+        this.owners[prefix] = from
+        this.rescope(shard)
+
+        return error
+    }
+
+    source.End()
+    destination.End()
+
+    this.rescope(from)
+
+    return nil
+}
+
+// Private methods
+
+func (this *ShardSet) path(key []byte) [csha256.Size]byte {
+    if len(this.salt) == 0 {
+        return sha256(key)
+    }
+
+    return sha256(this.salt, key)
+}
+
+func (this *ShardSet) prefix(path [csha256.Size]byte) int {
+    prefix := 0
+
+    for index := 0; index < this.bits; index++ {
+        prefix <<= 1
+
+        if bit(path[:], index) {
+            prefix |= 1
+        }
+    }
+
+    return prefix
+}
+
+func (this *ShardSet) rescope(shard int) {
+    collection := this.Shard(shard)
+    collection.Scope.None()
+
+    for prefix := 0; prefix < len(this.owners); prefix++ {
+        if this.owners[prefix] == shard {
+            value := make([]byte, csha256.Size)
+
+            for index := 0; index < this.bits; index++ {
+                setbit(value, index, ((prefix >> uint(this.bits - index - 1)) & 1) == 1)
+            }
+
+            collection.Scope.Add(value, this.bits)
+        }
+    }
+}
+
+func (this *ShardSet) summary(prefix int, depth int) (shardsummary, error) {
+    if depth < this.bits {
+        left, lefterror := this.summary(2 * prefix, depth + 1)
+
+        if lefterror != nil {
+            return shardsummary{}, lefterror
+        }
+
+        right, righterror := this.summary(2 * prefix + 1, depth + 1)
+
+        if righterror != nil {
+            return shardsummary{}, righterror
+        }
+
+        return this.combine(left, right, false)
+    }
+
+    cursor := this.Shard(this.owners[prefix]).root
+
+    for index := 0; index < this.bits; index++ {
+        if !(cursor.known) {
+            return shardsummary{}, errors.New("Shard has unknown nodes on prefix path.")
+        }
+
+        if cursor.leaf() {
+            break
+        }
+
+        if ((prefix >> uint(this.bits - index - 1)) & 1) == 1 {
+            cursor = cursor.children.right
+        } else {
+            cursor = cursor.children.left
+        }
+    }
+
+    if !(cursor.known) {
+        return shardsummary{}, errors.New("Shard has unknown nodes on prefix path.")
+    }
+
+    if cursor.leaf() {
+        if cursor.placeholder() || (this.prefix(this.path(cursor.key)) != prefix) {
+            return shardsummary{empty: true}, nil
+        }
+
+        return shardsummary{false, true, cursor.label, cursor.values}, nil
+    }
+
+    return shardsummary{false, false, cursor.label, cursor.values}, nil
+}
+
+func (this *ShardSet) combine(left shardsummary, right shardsummary, root bool) (shardsummary, error) {
+    if !root {
+        if left.empty && right.empty {
+            return left, nil
+        }
+
+        if left.empty && right.leaf {
+            return right, nil
+        }
+
+        if right.empty && left.leaf {
+            return left, nil
+        }
+    }
+
+    left = this.fill(left)
+    right = this.fill(right)
+
+    values := make([][]byte, len(this.fields))
+
+    for index := 0; index < len(this.fields); index++ {
+        parentvalue, parenterror := this.fields[index].Parent(left.values[index], right.values[index])
+
+        if parenterror != nil {
+            return shardsummary{}, parenterror
+        }
+
+        values[index] = parentvalue
+    }
+
+    return shardsummary{false, false, sha256(false, values, left.label[:], right.label[:]), values}, nil
+}
+
+func (this *ShardSet) fill(summary shardsummary) shardsummary {
+    if !(summary.empty) {
+        return summary
+    }
+
+    values := make([][]byte, len(this.fields))
+
+    for index := 0; index < len(this.fields); index++ {
+        values[index] = this.fields[index].Placeholder()
+    }
+
+    return shardsummary{false, true, sha256(true, []byte{}, values), values}
+}
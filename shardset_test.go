@@ -0,0 +1,228 @@
+package collection
+
+import "testing"
+import "encoding/binary"
+
+func TestShardSetEmptyShardSet(test *testing.T) {
+    ctx := testctx("[shardset.go]", test)
+
+    stake64 := Stake64{}
+    set := EmptyShardSet(3, 4, stake64)
+
+    if len(set.shards) != 3 {
+        test.Error("[shardset.go]", "[constructors]", "EmptyShardSet() creates the wrong number of shards.")
+    }
+
+    if len(set.owners) != 16 {
+        test.Error("[shardset.go]", "[constructors]", "EmptyShardSet() creates the wrong number of prefixes.")
+    }
+
+    for index := 0; index < 3; index++ {
+        if len(set.shards[index].Scope.masks) == 0 {
+            test.Error("[shardset.go]", "[constructors]", "EmptyShardSet() does not assign prefixes to every shard.")
+        }
+    }
+
+    reference := EmptyCollection(stake64)
+    root, error := set.Root()
+
+    if (error != nil) || (root != reference.root.label) {
+        test.Error("[shardset.go]", "[root]", "Empty shard set root differs from the empty collection root.")
+    }
+
+    ctx.should_panic("[constructors]", func() {
+        EmptyShardSet(0, 4, stake64)
+    })
+
+    ctx.should_panic("[constructors]", func() {
+        EmptyShardSet(2, 0, stake64)
+    })
+}
+
+func TestShardSetManipulators(test *testing.T) {
+    ctx := testctx("[shardset.go]", test)
+
+    stake64 := Stake64{}
+    data := Data{}
+
+    set := EmptyShardSet(4, 3, stake64, data)
+    reference := EmptyCollection(stake64, data)
+
+    for _, count := range([]int{0, 1, 2, 3, 5, 17, 256}) {
+        set = EmptyShardSet(4, 3, stake64, data)
+        reference = EmptyCollection(stake64, data)
+
+        for index := 0; index < count; index++ {
+            key := make([]byte, 8)
+            binary.BigEndian.PutUint64(key, uint64(index))
+
+            set.Add(key, uint64(index), key)
+            reference.Add(key, uint64(index), key)
+        }
+
+        root, error := set.Root()
+
+        if (error != nil) || (root != reference.root.label) {
+            test.Error("[shardset.go]", "[root]", "Shard set root differs from the reference collection root.")
+        }
+    }
+
+    for index := 0; index < 256; index += 3 {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        set.Set(key, uint64(2 * index), key)
+        reference.Set(key, uint64(2 * index), key)
+    }
+
+    for index := 1; index < 256; index += 3 {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        set.Remove(key)
+        reference.Remove(key)
+    }
+
+    set.SetField([]byte{0, 0, 0, 0, 0, 0, 0, 2}, 0, uint64(99))
+    reference.SetField([]byte{0, 0, 0, 0, 0, 0, 0, 2}, 0, uint64(99))
+
+    root, error := set.Root()
+
+    if (error != nil) || (root != reference.root.label) {
+        test.Error("[shardset.go]", "[root]", "Shard set root differs from the reference collection root after Set() and Remove().")
+    }
+
+    for index := 0; index < 256; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        owner := set.Owner(key)
+        ctx.verify.tree("[manipulators]", set.Shard(owner))
+
+        record, _ := set.Get(key)
+
+        if record.Match() != (index % 3 != 1) {
+            test.Error("[shardset.go]", "[get]", "Get() does not route to the shard owning the key.")
+        }
+
+        for shard := 0; shard < 4; shard++ {
+            if shard != owner {
+                ctx.verify.nokey("[manipulators]", set.Shard(shard), key)
+            }
+        }
+    }
+}
+
+func TestShardSetMove(test *testing.T) {
+    ctx := testctx("[shardset.go]", test)
+
+    stake64 := Stake64{}
+
+    set := EmptyShardSet(2, 2, stake64)
+    reference := EmptyCollection(stake64)
+
+    for index := 0; index < 128; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        set.Add(key, uint64(index))
+        reference.Add(key, uint64(index))
+    }
+
+    for prefix := 0; prefix < 4; prefix++ {
+        if set.Move(prefix, 1) != nil {
+            test.Error("[shardset.go]", "[move]", "Move() yields an error on a valid prefix.")
+        }
+
+        root, error := set.Root()
+
+        if (error != nil) || (root != reference.root.label) {
+            test.Error("[shardset.go]", "[move]", "Move() alters the shard set root.")
+        }
+    }
+
+    if set.shards[1].root.label != reference.root.label {
+        test.Error("[shardset.go]", "[move]", "Moving every prefix to a shard does not gather all records.")
+    }
+
+    if set.shards[0].root.label != EmptyCollection(stake64).root.label {
+        test.Error("[shardset.go]", "[move]", "Move() does not remove records from the source shard.")
+    }
+
+    if len(set.shards[0].Scope.masks) != 0 || len(set.shards[1].Scope.masks) != 4 {
+        test.Error("[shardset.go]", "[move]", "Move() does not update shard scopes.")
+    }
+
+    set.Move(2, 0)
+
+    for index := 0; index < 128; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        record, _ := set.Get(key)
+
+        if !(record.Match()) {
+            test.Error("[shardset.go]", "[move]", "Get() does not find a record after Move().")
+        }
+
+        ctx.verify.values("[move]", set.Shard(set.Owner(key)), key, uint64(index))
+    }
+
+    ctx.should_panic("[move]", func() {
+        set.Move(4, 0)
+    })
+
+    ctx.should_panic("[move]", func() {
+        set.Move(0, 2)
+    })
+}
+
+func TestShardSetSalt(test *testing.T) {
+    ctx := testctx("[shardset.go]", test)
+
+    stake64 := Stake64{}
+
+    set := EmptyShardSet(3, 3, stake64)
+    set.Salt([]byte("salt"))
+
+    reference := EmptyCollection(stake64)
+    reference.Salt([]byte("salt"))
+
+    for index := 0; index < 128; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        if set.Add(key, uint64(index)) != nil {
+            test.Error("[shardset.go]", "[salt]", "Add() yields an error on a salted shard set.")
+        }
+
+        reference.Add(key, uint64(index))
+    }
+
+    root, error := set.Root()
+
+    if (error != nil) || (root != reference.root.label) {
+        test.Error("[shardset.go]", "[salt]", "Salted shard set root differs from the salted reference collection root.")
+    }
+
+    for prefix := 0; prefix < 8; prefix++ {
+        if set.Move(prefix, 0) != nil {
+            test.Error("[shardset.go]", "[salt]", "Move() yields an error on a salted shard set.")
+        }
+    }
+
+    if set.shards[0].root.label != reference.root.label {
+        test.Error("[shardset.go]", "[salt]", "Move() does not gather all records of a salted shard set.")
+    }
+
+    for index := 0; index < 128; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        ctx.verify.values("[salt]", set.Shard(set.Owner(key)), key, uint64(index))
+    }
+
+    ctx.should_panic("[salt]", func() {
+        set.Salt([]byte("pepper"))
+    })
+}
@@ -0,0 +1,46 @@
+package collection
+
+import "errors"
+
+// collection
+
+// Methods (collection) (simulate)
+
+func (this *collection) Simulate(update userupdate) (ChangeSet, error) {
+    if this.transaction.ongoing {
+        panic("Cannot simulate an update while a transaction is ongoing.")
+    }
+
+    overlay := this.Clone()
+    prepared, error := overlay.Prepare(update)
+
+    if error != nil {
+        return ChangeSet{}, error
+    }
+
+    overlay.Begin()
+
+    if !(update.Check(prepared.proxy)) {
+        return ChangeSet{}, errors.New("Update check failed.")
+    }
+
+    error = prepared.proxy.failed()
+
+    if error != nil {
+        return ChangeSet{}, error
+    }
+
+    update.Apply(prepared.proxy)
+    error = prepared.proxy.failed()
+
+    if error != nil {
+        return ChangeSet{}, error
+    }
+
+    root := overlay.root.committed().label
+    changes := overlay.changes()
+
+    overlay.relabel(false)
+
+    return ChangeSet{changes, root, overlay.root.label}, nil
+}
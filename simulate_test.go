@@ -0,0 +1,212 @@
+package collection
+
+import "testing"
+
+func TestSimulate(test *testing.T) {
+    ctx := testctx("[simulate.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 16; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    root := collection.root.label
+
+    from, _ := collection.Get([]byte{3}).Proof()
+    to, _ := collection.Get([]byte{7}).Proof()
+
+    pending, _ := collection.Prepare(TestUpdateSingleRecordUpdate{to})
+
+    changeset, error := collection.Simulate(TestUpdateDoubleRecordUpdate{from, to})
+
+    if error != nil {
+        test.Error("[simulate.go]", "[simulate]", "Simulate() yields an error on a valid update.")
+    }
+
+    if collection.root.label != root {
+        test.Error("[simulate.go]", "[simulate]", "Simulate() changes the root of the collection.")
+    }
+
+    if collection.transaction.ongoing {
+        test.Error("[simulate.go]", "[simulate]", "Simulate() leaves a transaction ongoing.")
+    }
+
+    ctx.verify.values("[simulate]", &collection, []byte{3}, uint64(3))
+    ctx.verify.values("[simulate]", &collection, []byte{7}, uint64(7))
+    ctx.verify.tree("[simulate]", &collection)
+
+    if (changeset.OldRoot != root) || (len(changeset.Changes) != 2) {
+        test.Error("[simulate.go]", "[changeset]", "Simulate() returns a wrong change set.")
+    }
+
+    if collection.Apply(pending) != nil {
+        test.Error("[simulate.go]", "[transaction]", "Simulate() invalidates updates prepared before it.")
+    }
+
+    collection.Set([]byte{7}, uint64(7))
+
+    reference := collection.Clone()
+    reference.Apply(TestUpdateDoubleRecordUpdate{from, to})
+
+    if changeset.NewRoot != reference.root.label {
+        test.Error("[simulate.go]", "[root]", "Simulate() does not return the root the update would yield.")
+    }
+
+    verifier := EmptyVerifier(stake64)
+    verifier.root.label = collection.root.label
+
+    changeset, error = verifier.Simulate(TestUpdateDoubleRecordUpdate{from, to})
+
+    if (error != nil) || (changeset.NewRoot != reference.root.label) {
+        test.Error("[simulate.go]", "[verifier]", "Simulate() does not work on a verifier holding only the records of the update.")
+    }
+
+    if (verifier.root.label != collection.root.label) || verifier.root.known {
+        test.Error("[simulate.go]", "[verifier]", "Simulate() does not restore the verifier.")
+    }
+
+    empty, _ := collection.Get([]byte{0}).Proof()
+
+    if _, error := collection.Simulate(TestUpdateDoubleRecordUpdate{empty, to}); error == nil {
+        test.Error("[simulate.go]", "[check]", "Simulate() does not yield an error when the update check fails.")
+    }
+
+    from.steps[0].Left.Label[0]++
+
+    if _, error := verifier.Simulate(TestUpdateDoubleRecordUpdate{from, to}); error == nil {
+        test.Error("[simulate.go]", "[proof]", "Simulate() does not yield an error on an invalid proof.")
+    }
+
+    if verifier.root.known {
+        test.Error("[simulate.go]", "[proof]", "Simulate() keeps the records of an invalid update.")
+    }
+
+    collection.Begin()
+
+    ctx.should_panic("[ongoing]", func() {
+        collection.Simulate(TestUpdateSingleRecordUpdate{to})
+    })
+
+    collection.End()
+}
+
+func TestSimulateUntouched(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 16; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    from, _ := collection.Get([]byte{3}).Proof()
+    to, _ := collection.Get([]byte{7}).Proof()
+
+    verifier := EmptyVerifier(stake64)
+    verifier.root.label = collection.root.label
+    verifier.AutoCollect.Disable()
+    verifier.Cache.Nodes(64)
+
+    verifier.Verify(from)
+
+    type state struct {
+        known bool
+        access uint64
+    }
+
+    snapshot := func() []state {
+        states := []state{}
+
+        var explore func(*node)
+        explore = func(node *node) {
+            states = append(states, state{node.known, node.access})
+
+            if !(node.leaf()) {
+                explore(node.children.left)
+                explore(node.children.right)
+            }
+        }
+
+        explore(verifier.root)
+        return states
+    }
+
+    before := snapshot()
+    clock := verifier.Cache.clock
+    id := verifier.transaction.id
+
+    if _, error := verifier.Simulate(TestUpdateDoubleRecordUpdate{from, to}); error != nil {
+        test.Error("[simulate.go]", "[untouched]", "Simulate() yields an error on a valid update.")
+    }
+
+    after := snapshot()
+
+    if len(before) != len(after) {
+        test.Fatal("[simulate.go]", "[untouched]", "Simulate() changes the shape of the known tree.")
+    }
+
+    for index := 0; index < len(before); index++ {
+        if before[index] != after[index] {
+            test.Error("[simulate.go]", "[untouched]", "Simulate() changes the known nodes or their cache stamps.")
+            break
+        }
+    }
+
+    if (verifier.Cache.clock != clock) || (verifier.transaction.id != id) {
+        test.Error("[simulate.go]", "[untouched]", "Simulate() changes the cache clock or the transaction counter.")
+    }
+}
+
+type TestSimulateMoveUpdate struct {
+    from Proof
+    to Proof
+}
+
+func (this TestSimulateMoveUpdate) Records() []Proof {
+    return []Proof{this.from, this.to}
+}
+
+func (this TestSimulateMoveUpdate) Check(collection ReadOnly) bool {
+    return true
+}
+
+func (this TestSimulateMoveUpdate) Apply(collection ReadWrite) {
+    collection.Remove(this.from.Key())
+    collection.Set(this.to.Key(), uint64(999))
+}
+
+func TestSimulateOverlay(test *testing.T) {
+    ctx := testctx("[simulate.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 32; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    root := collection.root.label
+
+    for from := 0; from < 32; from++ {
+        for to := 0; to < 32; to++ {
+            if from == to {
+                continue
+            }
+
+            fromproof, _ := collection.Get([]byte{byte(from)}).Proof()
+            toproof, _ := collection.Get([]byte{byte(to)}).Proof()
+
+            if _, error := collection.Simulate(TestSimulateMoveUpdate{fromproof, toproof}); error != nil {
+                test.Error("[simulate.go]", "[overlay]", "Simulate() yields an error on a valid update.")
+            }
+
+            if collection.root.label != root {
+                test.Fatal("[simulate.go]", "[overlay]", "Simulate() changes the root of the collection.")
+            }
+
+            ctx.verify.tree("[overlay]", &collection)
+            ctx.verify.values("[overlay]", &collection, []byte{byte(to)}, uint64(to))
+        }
+    }
+}
@@ -0,0 +1,142 @@
+package collection
+
+import "errors"
+import csha256 "crypto/sha256"
+import "github.com/dedis/protobuf"
+
+// snapshotnode
+
+type snapshotnode struct {
+    Known bool
+    Dump dump
+}
+
+// collection
+
+// Methods (collection) (snapshot)
+
+func (this *collection) Save() []byte {
+    if this.transaction.ongoing {
+        panic("Cannot save a collection while a transaction is ongoing.")
+    }
+
+    nodes := []snapshotnode{}
+
+    var explore func(*node)
+    explore = func(node *node) {
+        if !(node.known) {
+            nodes = append(nodes, snapshotnode{Known: false})
+            return
+        }
+
+        nodes = append(nodes, snapshotnode{true, dumpnode(node)})
+
+        if !(node.leaf()) {
+            explore(node.children.left)
+            explore(node.children.right)
+        }
+    }
+
+    explore(this.root)
+
+    serializable := struct {
+        Label [csha256.Size]byte
+        Salt []byte
+        Scope []byte
+        Nodes []snapshotnode
+    }{this.root.label, this.salt, this.Scope.Serialize(), nodes}
+
+    buffer, _ := protobuf.Encode(&serializable)
+    return buffer
+}
+
+func (this *collection) Load(buffer []byte) error {
+    if this.transaction.ongoing {
+        panic("Cannot load a collection while a transaction is ongoing.")
+    }
+
+    deserializable := struct {
+        Label [csha256.Size]byte
+        Salt []byte
+        Scope []byte
+        Nodes []snapshotnode
+    }{}
+
+    err := protobuf.Decode(buffer, &deserializable)
+
+    if err != nil {
+        return err
+    }
+
+    if (len(this.pins) > 0) && !(equal(this.salt, deserializable.Salt)) {
+        return errors.New("Cannot load a snapshot with a different salt while keys are pinned.")
+    }
+
+    scope := Scope{}
+    err = scope.Deserialize(deserializable.Scope)
+
+    if err != nil {
+        return err
+    }
+
+    root := new(node)
+    root.label = deserializable.Label
+
+    position := 0
+
+    var explore func(*node) error
+    explore = func(node *node) error {
+        if position >= len(deserializable.Nodes) {
+            return errors.New("Snapshot is truncated.")
+        }
+
+        entry := deserializable.Nodes[position]
+        position++
+
+        if !(entry.Known) {
+            return nil
+        }
+
+        if (entry.Dump.Label != node.label) || !(entry.Dump.consistent()) {
+            return errors.New("Snapshot node does not match its label.")
+        }
+
+        error := this.validate(entry.Dump.Values)
+
+        if error != nil {
+            return error
+        }
+
+        entry.Dump.to(node)
+
+        if node.leaf() {
+            return nil
+        }
+
+        lefterror := explore(node.children.left)
+
+        if lefterror != nil {
+            return lefterror
+        }
+
+        return explore(node.children.right)
+    }
+
+    err = explore(root)
+
+    if err != nil {
+        return err
+    }
+
+    if position != len(deserializable.Nodes) {
+        return errors.New("Snapshot has trailing nodes.")
+    }
+
+    this.root = root
+    this.salt = deserializable.Salt
+    this.Scope = scope
+    this.Scope.salted(this.salt)
+    this.reindex()
+
+    return nil
+}
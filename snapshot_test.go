@@ -0,0 +1,154 @@
+package collection
+
+import "testing"
+
+func TestSnapshotSaveLoad(test *testing.T) {
+    stake64 := Stake64{}
+    data := Data{}
+
+    collection := EmptyCollection(stake64, data)
+
+    for index := 0; index < 64; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index), []byte{byte(2 * index)})
+    }
+
+    collection.Scope.None()
+    collection.Scope.Add([]byte{0x00}, 1)
+    collection.Scope.AddKey([]byte{42})
+    collection.Collect()
+
+    buffer := collection.Save()
+
+    restored := EmptyCollection(stake64, data)
+    restored.Index(1)
+
+    error := restored.Load(buffer)
+
+    if error != nil {
+        test.Error("[snapshot.go]", "[load]", "Load() yields an error on a valid snapshot.")
+    }
+
+    if restored.root.label != collection.root.label {
+        test.Error("[snapshot.go]", "[load]", "Load() does not restore the root label.")
+    }
+
+    if !(restored.Scope.Equal(collection.Scope)) || !(restored.Scope.Contains([]byte{42})) {
+        test.Error("[snapshot.go]", "[load]", "Load() does not restore the scope.")
+    }
+
+    for index := 0; index < 64; index++ {
+        key := []byte{byte(index)}
+
+        _, expectederror := collection.Get(key).Record()
+        record, error := restored.Get(key).Record()
+
+        if (error == nil) != (expectederror == nil) {
+            test.Error("[snapshot.go]", "[load]", "Load() does not restore the same set of known records.")
+        }
+
+        if error == nil {
+            values, _ := record.Values()
+
+            if values[0].(uint64) != uint64(index) {
+                test.Error("[snapshot.go]", "[load]", "Load() does not restore the values of known records.")
+            }
+        }
+    }
+
+    if len(restored.Lookup(1, []byte{84})) != 1 {
+        test.Error("[snapshot.go]", "[load]", "Load() does not rebuild the indexes of the collection.")
+    }
+
+    restored.Scope.All()
+    restored.Collect()
+
+    if restored.Add([]byte{42}, uint64(1), []byte{}) == nil {
+        test.Error("[snapshot.go]", "[load]", "Loaded collection accepts a colliding key.")
+    }
+}
+
+func TestSnapshotSalt(test *testing.T) {
+    stake64 := Stake64{}
+
+    collection := EmptyCollection(stake64)
+    collection.Salt([]byte("mysalt"))
+
+    for index := 0; index < 16; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    collection.Scope.None()
+    collection.Scope.AddKey([]byte{3})
+    collection.Collect()
+
+    buffer := collection.Save()
+
+    restored := EmptyCollection(stake64)
+
+    if restored.Load(buffer) != nil {
+        test.Fatal("[snapshot.go]", "[salt]", "Load() yields an error on a salted snapshot.")
+    }
+
+    if !(restored.Scope.Contains([]byte{3})) || !(restored.InScope([]byte{3})) {
+        test.Error("[snapshot.go]", "[salt]", "Load() does not salt the restored scope.")
+    }
+
+    pinned := EmptyCollection(stake64)
+    pinned.Pin([]byte{5})
+
+    if pinned.Load(buffer) == nil {
+        test.Error("[snapshot.go]", "[salt]", "Load() replaces the salt of a collection with pinned keys.")
+    }
+
+    if (len(pinned.salt) != 0) || !(pinned.Pinned([]byte{5})) {
+        test.Error("[snapshot.go]", "[salt]", "Load() alters a collection it refuses to load.")
+    }
+}
+
+func TestSnapshotMalformed(test *testing.T) {
+    ctx := testctx("[snapshot.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    collection.Add([]byte("alice"), uint64(1))
+    collection.Add([]byte("bob"), uint64(2))
+
+    buffer := collection.Save()
+
+    truncated := EmptyCollection(stake64)
+
+    if truncated.Load(buffer[:len(buffer) / 2]) == nil {
+        test.Error("[snapshot.go]", "[load]", "Load() accepts a truncated snapshot.")
+    }
+
+    other := EmptyCollection(stake64)
+    other.Add([]byte("carol"), uint64(3))
+
+    label := other.root.label
+    other.root.label = collection.root.label
+    forged := other.Save()
+    other.root.label = label
+
+    restored := EmptyCollection(stake64)
+
+    if restored.Load(forged) == nil {
+        test.Error("[snapshot.go]", "[load]", "Load() accepts nodes that do not match the root label.")
+    }
+
+    if restored.root.label != EmptyCollection(stake64).root.label {
+        test.Error("[snapshot.go]", "[load]", "Load() alters the collection on a failed load.")
+    }
+
+    collection.Begin()
+
+    ctx.should_panic("[save]", func() {
+        collection.Save()
+    })
+
+    ctx.should_panic("[load]", func() {
+        collection.Load(buffer)
+    })
+
+    collection.End()
+}
@@ -0,0 +1,135 @@
+package collection
+
+import "errors"
+
+// ProofSource
+
+type ProofSource interface {
+    Proof([]byte) (Proof, error)
+}
+
+// collectionsource
+
+type collectionsource struct {
+    collection *collection
+}
+
+// Constructors
+
+func CollectionSource(collection *collection) ProofSource {
+    return collectionsource{collection}
+}
+
+// Methods
+
+func (this collectionsource) Proof(key []byte) (Proof, error) {
+    proof, error := this.collection.Get(key).Proof()
+
+    if error != nil {
+        return Proof{}, error
+    }
+
+    return this.collection.Deserialize(this.collection.Serialize(proof))
+}
+
+// collection
+
+// Private methods (collection) (source)
+
+func (this *collection) fetch(key []byte) error {
+    if (this.Source == nil) || this.known(key) {
+        return nil
+    }
+
+    proof, error := this.Source.Proof(key)
+
+    if error != nil {
+        return error
+    }
+
+    if !(equal(proof.key, key)) {
+        return errors.New("Source returned a proof for the wrong key.")
+    }
+
+    return this.graft(proof)
+}
+
+func (this *collection) known(key []byte) bool {
+    path := this.path(key)
+
+    depth := 0
+    cursor := this.root
+
+    for {
+        if !(cursor.known) {
+            return false
+        }
+
+        if cursor.leaf() {
+            return true
+        }
+
+        if !(cursor.children.left.known) || !(cursor.children.right.known) {
+            return false
+        }
+
+        if bit(path[:], depth) {
+            cursor = cursor.children.right
+        } else {
+            cursor = cursor.children.left
+        }
+
+        depth++
+    }
+}
+
+func (this *collection) graft(proof Proof) error {
+    error := this.wellformed(proof)
+
+    if error != nil {
+        return error
+    }
+
+    if !(proof.consistent()) {
+        return errors.New("Invalid proof from source.")
+    }
+
+    learn := func(node *node, dump dump) {
+        if !(node.known) && (node.label == dump.Label) {
+            dump.to(node)
+            this.indexlearn(node)
+        }
+    }
+
+    learn(this.root, proof.root)
+
+    path := this.path(proof.key)
+    cursor := this.root
+
+    for depth := 0; depth < len(proof.steps); depth++ {
+        if !(cursor.known) || cursor.leaf() {
+            break
+        }
+
+        learn(cursor.children.left, proof.steps[depth].Left)
+        learn(cursor.children.right, proof.steps[depth].Right)
+
+        if bit(path[:], depth) {
+            cursor = cursor.children.right
+        } else {
+            cursor = cursor.children.left
+        }
+    }
+
+    if !(this.known(proof.key)) {
+        return errors.New("Proof from source does not match the collection.")
+    }
+
+    this.touch(cursor)
+
+    if this.Cache.enabled() {
+        this.evict()
+    }
+
+    return nil
+}
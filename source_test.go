@@ -0,0 +1,162 @@
+package collection
+
+import "testing"
+import "errors"
+
+type testsource struct {
+    proof Proof
+    error error
+}
+
+func (this testsource) Proof(key []byte) (Proof, error) {
+    return this.proof, this.error
+}
+
+func TestSourceCollectionSource(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    collection.Add([]byte("alice"), uint64(1))
+
+    source := CollectionSource(&collection)
+    proof, error := source.Proof([]byte("alice"))
+
+    if error != nil {
+        test.Error("[source.go]", "[proof]", "CollectionSource yields an error on a known key.")
+    }
+
+    if !(proof.Match()) || !(equal(proof.Key(), []byte("alice"))) {
+        test.Error("[source.go]", "[proof]", "CollectionSource returns a wrong proof.")
+    }
+
+    proof.steps[0].Left.Values[0][0] ^= 1
+    proof.steps[0].Right.Values[0][0] ^= 1
+
+    record, _ := collection.Get([]byte("alice")).Record()
+
+    if values, _ := record.Values(); values[0].(uint64) != 1 {
+        test.Error("[source.go]", "[proof]", "CollectionSource returns proofs sharing memory with the collection.")
+    }
+
+    collection.Scope.None()
+    collection.Collect()
+
+    if _, error := source.Proof([]byte("alice")); error == nil {
+        test.Error("[source.go]", "[proof]", "CollectionSource does not forward errors from the collection.")
+    }
+}
+
+func TestSourceFetch(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 16; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    verifier := EmptyVerifier(stake64)
+    verifier.root.label = collection.root.label
+    verifier.Source = CollectionSource(&collection)
+
+    record, error := verifier.Get([]byte{3}).Record()
+
+    if error != nil {
+        test.Error("[source.go]", "[record]", "Record() does not fetch unknown subtrees from the source.")
+    } else if values, _ := record.Values(); values[0].(uint64) != 3 {
+        test.Error("[source.go]", "[record]", "Record() returns wrong values after fetching from the source.")
+    }
+
+    verifier.Collect()
+
+    if _, error := verifier.Get([]byte{42}).Proof(); error != nil {
+        test.Error("[source.go]", "[proof]", "Proof() does not fetch unknown subtrees from the source.")
+    }
+
+    verifier.Collect()
+
+    if verifier.Set([]byte{5}, uint64(50)) != nil {
+        test.Error("[source.go]", "[set]", "Set() does not fetch unknown subtrees from the source.")
+    }
+
+    collection.Set([]byte{5}, uint64(50))
+
+    if verifier.Add([]byte{42}, uint64(42)) != nil {
+        test.Error("[source.go]", "[add]", "Add() does not fetch unknown subtrees from the source.")
+    }
+
+    collection.Add([]byte{42}, uint64(42))
+
+    if verifier.Remove([]byte{7}) != nil {
+        test.Error("[source.go]", "[remove]", "Remove() does not fetch unknown subtrees from the source.")
+    }
+
+    collection.Remove([]byte{7})
+
+    if verifier.root.label != collection.root.label {
+        test.Error("[source.go]", "[fetch]", "Updates applied with fetched subtrees yield a wrong root.")
+    }
+
+    verifier.Collect()
+    verifier.Begin()
+
+    if verifier.Set([]byte{1}, uint64(10)) != nil {
+        test.Error("[source.go]", "[transaction]", "Set() does not fetch unknown subtrees from the source during a transaction.")
+    }
+
+    if verifier.Set([]byte{2}, uint64(20)) != nil {
+        test.Error("[source.go]", "[transaction]", "Set() does not fetch unknown subtrees on an inconsistent root.")
+    }
+
+    verifier.End()
+
+    collection.Set([]byte{1}, uint64(10))
+    collection.Set([]byte{2}, uint64(20))
+
+    if verifier.root.label != collection.root.label {
+        test.Error("[source.go]", "[transaction]", "Transaction applied with fetched subtrees yields a wrong root.")
+    }
+}
+
+func TestSourceInvalid(test *testing.T) {
+    stake64 := Stake64{}
+
+    collection := EmptyCollection(stake64)
+    collection.Add([]byte("alice"), uint64(1))
+
+    other := EmptyCollection(stake64)
+    other.Add([]byte("alice"), uint64(2))
+
+    verifier := EmptyVerifier(stake64)
+    verifier.root.label = collection.root.label
+
+    otherproof, _ := other.Get([]byte("alice")).Proof()
+    verifier.Source = testsource{otherproof, nil}
+
+    if _, error := verifier.Get([]byte("alice")).Record(); error == nil {
+        test.Error("[source.go]", "[fetch]", "Record() accepts a proof that does not match the collection.")
+    }
+
+    if verifier.root.known {
+        test.Error("[source.go]", "[fetch]", "Fetching learns nodes from a proof that does not match the collection.")
+    }
+
+    proof, _ := collection.Get([]byte("alice")).Proof()
+    verifier.Source = testsource{proof, nil}
+
+    if verifier.Set([]byte("bob"), uint64(3)) == nil {
+        test.Error("[source.go]", "[fetch]", "Set() accepts a proof for the wrong key.")
+    }
+
+    proof.steps[0].Left.Values[0] = []byte{1}
+    verifier.Source = testsource{proof, nil}
+
+    if verifier.Remove([]byte("alice")) == nil {
+        test.Error("[source.go]", "[fetch]", "Remove() accepts an inconsistent proof.")
+    }
+
+    verifier.Source = testsource{Proof{}, errors.New("Source unavailable.")}
+
+    if verifier.Add([]byte("carol"), uint64(4)) == nil {
+        test.Error("[source.go]", "[fetch]", "Add() does not forward errors from the source.")
+    }
+}
@@ -0,0 +1,74 @@
+package collection
+
+// Stats
+
+type Stats struct {
+    Leaves int
+    Placeholders int
+
+    Known int
+    Unknown int
+
+    MaxDepth int
+    AvgDepth float64
+    Depths []int
+
+    KeyBytes int
+    ValueBytes int
+    Bytes int
+}
+
+// collection
+
+// Methods (collection) (stats)
+
+func (this *collection) Stats() (stats Stats) {
+    stats.Depths = []int{}
+    total := 0
+
+    var explore func(*node, int)
+    explore = func(node *node, depth int) {
+        if !(node.known) {
+            stats.Unknown++
+            return
+        }
+
+        stats.Known++
+        stats.Bytes += node.size()
+        stats.KeyBytes += len(node.key)
+
+        for index := 0; index < len(node.values); index++ {
+            stats.ValueBytes += len(node.values[index])
+        }
+
+        if node.leaf() {
+            if node.placeholder() {
+                stats.Placeholders++
+            } else {
+                stats.Leaves++
+            }
+
+            for len(stats.Depths) <= depth {
+                stats.Depths = append(stats.Depths, 0)
+            }
+
+            stats.Depths[depth]++
+            total += depth
+
+            if depth > stats.MaxDepth {
+                stats.MaxDepth = depth
+            }
+        } else {
+            explore(node.children.left, depth + 1)
+            explore(node.children.right, depth + 1)
+        }
+    }
+
+    explore(this.root, 0)
+
+    if leaves := stats.Leaves + stats.Placeholders; leaves > 0 {
+        stats.AvgDepth = float64(total) / float64(leaves)
+    }
+
+    return
+}
@@ -0,0 +1,65 @@
+package collection
+
+import "testing"
+
+func TestStats(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    stats := collection.Stats()
+
+    if (stats.Leaves != 0) || (stats.Placeholders != 2) || (stats.Known != 3) || (stats.Unknown != 0) {
+        test.Error("[stats.go]", "[stats]", "Stats() returns wrong node counts on an empty collection.")
+    }
+
+    if (stats.MaxDepth != 1) || (stats.AvgDepth != 1) || (len(stats.Depths) != 2) || (stats.Depths[1] != 2) {
+        test.Error("[stats.go]", "[stats]", "Stats() returns wrong depths on an empty collection.")
+    }
+
+    if (stats.KeyBytes != 0) || (stats.ValueBytes != 24) || (stats.Bytes != 3 * 32 + 24) {
+        test.Error("[stats.go]", "[stats]", "Stats() returns wrong byte sizes on an empty collection.")
+    }
+
+    for index := 0; index < 64; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    stats = collection.Stats()
+
+    if (stats.Leaves != 64) || (stats.Known != 2 * (stats.Leaves + stats.Placeholders) - 1) || (stats.KeyBytes != 64) {
+        test.Error("[stats.go]", "[stats]", "Stats() returns wrong node counts.")
+    }
+
+    leaves := 0
+    total := 0
+
+    for depth, count := range(stats.Depths) {
+        leaves += count
+        total += depth * count
+    }
+
+    if (leaves != stats.Leaves + stats.Placeholders) || (len(stats.Depths) != stats.MaxDepth + 1) || (stats.AvgDepth != float64(total) / float64(leaves)) {
+        test.Error("[stats.go]", "[stats]", "Stats() returns inconsistent depths.")
+    }
+
+    if stats.ValueBytes != 8 * stats.Known {
+        test.Error("[stats.go]", "[stats]", "Stats() returns wrong value sizes.")
+    }
+
+    collection.Scope.None()
+    collection.Scope.Add([]byte{0x00}, 1)
+    collection.Collect()
+
+    stats = collection.Stats()
+
+    if (stats.Unknown == 0) || (stats.Leaves >= 64) {
+        test.Error("[stats.go]", "[stats]", "Stats() does not count unknown nodes.")
+    }
+
+    verifier := EmptyVerifier(stake64)
+    stats = verifier.Stats()
+
+    if (stats.Known != 0) || (stats.Unknown != 1) || (stats.Bytes != 0) || (stats.AvgDepth != 0) {
+        test.Error("[stats.go]", "[stats]", "Stats() returns wrong statistics on an unknown root.")
+    }
+}
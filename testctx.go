@@ -93,7 +93,7 @@ func (this testctxverifier) treerecursion(prefix string, collection *collection,
     if node.leaf() {
         if !(node.placeholder()) {
             for index := 0; index < len(path); index++ {
-                keyhash := sha256(node.key)
+                keyhash := collection.path(node.key)
                 if path[index] != bit(keyhash[:], index) {
                     this.test.Error(this.file, prefix, "Leaf node on wrong path.")
                 }
@@ -129,7 +129,7 @@ func (this testctxverifier) scoperecursion(prefix string, collection *collection
         setbit(pathbuf[:], index, path[index])
     }
 
-    if node.known && len(path) > 1 && !(collection.Scope.match(pathbuf, len(path) - 2)) {
+    if node.known && len(path) > 1 && !(collection.keep(pathbuf, len(path) - 2)) {
         this.test.Error(this.file, prefix, "Out-of-scope node was not pruned from tree.")
     } else {
         if !(node.leaf()) {
@@ -153,12 +153,12 @@ func (this testctxverifier) scope(prefix string, collection *collection) {
     none := true
 
     setbit(pathbuf[:], 0, false)
-    if collection.Scope.match(pathbuf, 0) {
+    if collection.keep(pathbuf, 0) {
         none = false
     }
 
     setbit(pathbuf[:], 0, true)
-    if collection.Scope.match(pathbuf, 0) {
+    if collection.keep(pathbuf, 0) {
         none = false
     }
 
@@ -20,7 +20,11 @@ func (this *collection) Rollback() {
     var explore func(*node)
     explore = func(node *node) {
         if node.transaction.inconsistent || (node.transaction.backup != nil) {
-            node.restore()
+            for node.transaction.backup != nil {
+                node.restore()
+            }
+
+            node.transaction.inconsistent = false
 
             if !(node.leaf()) {
                 explore(node.children.left)
@@ -30,35 +34,177 @@ func (this *collection) Rollback() {
     }
 
     explore(this.root)
+    this.indexundo()
 
     this.transaction.id++
     this.transaction.ongoing = false
+    this.transaction.savepoints = 0
+}
+
+func (this *collection) End() ChangeSet {
+    changeset, error := this.Commit()
+
+    if error != nil {
+        panic("Journal append failed: " + error.Error())
+    }
+
+    return changeset
 }
 
-func (this *collection) End() {
+func (this *collection) Commit() (ChangeSet, error) {
     if !(this.transaction.ongoing) {
         panic("Transaction not in progress.")
     }
 
+    root := this.root.committed().label
+    changes := this.changes()
+
+    if this.Journal != nil {
+        this.relabel(true)
+
+        if len(changes) > 0 {
+            error := this.Journal.Append(ChangeSet{changes, root, this.root.label})
+
+            if error != nil {
+                this.Rollback()
+
+                if this.AutoCollect.value {
+                    this.Collect()
+                }
+
+                return ChangeSet{}, error
+            }
+        }
+    }
+
     this.confirm()
-    this.fix()
+    this.relabel(false)
+
+    changeset := ChangeSet{changes, root, this.root.label}
+
+    for _, change := range(changeset.Changes) {
+        this.bump(change.Key)
+    }
 
     if this.AutoCollect.value {
         this.Collect()
     }
 
+    this.indexconfirm()
+
     this.transaction.id++
     this.transaction.ongoing = false
+    this.transaction.savepoints = 0
+
+    return changeset, nil
+}
+
+func (this *collection) Savepoint() int {
+    if !(this.transaction.ongoing) {
+        panic("Transaction not in progress.")
+    }
+
+    this.transaction.savepoints++
+    this.indexmark()
+
+    return this.transaction.savepoints
+}
+
+func (this *collection) RollbackTo(savepoint int) {
+    if !(this.transaction.ongoing) {
+        panic("Transaction not in progress.")
+    }
+
+    if (savepoint < 1) || (savepoint > this.transaction.savepoints) {
+        panic("Savepoint unknown.")
+    }
+
+    var explore func(*node)
+    explore = func(node *node) {
+        if node.transaction.inconsistent || (node.transaction.backup != nil) {
+            for (node.transaction.backup != nil) && (node.transaction.savepoint >= savepoint) {
+                node.restore()
+            }
+
+            if !(node.leaf()) {
+                explore(node.children.left)
+                explore(node.children.right)
+            }
+        }
+    }
+
+    explore(this.root)
+    this.indexrewind(savepoint)
+
+    this.transaction.savepoints = savepoint
+}
+
+func (this *collection) Release(savepoint int) {
+    if !(this.transaction.ongoing) {
+        panic("Transaction not in progress.")
+    }
+
+    if (savepoint < 1) || (savepoint > this.transaction.savepoints) {
+        panic("Savepoint unknown.")
+    }
+
+    var explore func(*node)
+    explore = func(cursor *node) {
+        if cursor.transaction.inconsistent || (cursor.transaction.backup != nil) {
+            var oldest *node
+
+            for (cursor.transaction.backup != nil) && (cursor.transaction.savepoint >= savepoint) {
+                oldest = cursor.transaction.backup
+
+                cursor.transaction.backup = oldest.transaction.backup
+                cursor.transaction.savepoint = oldest.transaction.savepoint
+            }
+
+            if (oldest != nil) && !((cursor.transaction.backup != nil) && (cursor.transaction.savepoint == savepoint - 1)) {
+                oldest.transaction.backup = cursor.transaction.backup
+                oldest.transaction.savepoint = cursor.transaction.savepoint
+
+                cursor.transaction.backup = oldest
+                cursor.transaction.savepoint = savepoint - 1
+            }
+
+            if !(cursor.leaf()) {
+                explore(cursor.children.left)
+                explore(cursor.children.right)
+            }
+        }
+    }
+
+    explore(this.root)
+    this.indexrelease(savepoint)
+
+    this.transaction.savepoints = savepoint - 1
 }
 
 func (this *collection) Collect() {
+    this.sweep()
+
+    if this.Cache.enabled() {
+        this.evict()
+    }
+}
+
+// Private methods (collection) (transaction methods)
+
+func (this *collection) sweep() {
+    spare := func(node *node) bool {
+        return this.Cache.enabled() && (node.transaction.inconsistent || (node.transaction.backup != nil))
+    }
+
     var explore func(*node, [csha256.Size]byte, int)
     explore = func(node *node, path [csha256.Size]byte, bit int) {
         if !(node.known) {
             return
         }
 
-        if bit > 0 && !(this.Scope.match(path, bit - 1)) {
+        if bit > 0 && !(spare(node)) && !(this.keep(path, bit - 1)) {
+            this.indexforget(node)
+
             node.known = false
             node.key = []byte{}
             node.values = [][]byte{}
@@ -81,16 +227,18 @@ func (this *collection) Collect() {
     none := true
 
     setbit(path[:], 0, false)
-    if this.Scope.match(path, 0) {
+    if this.keep(path, 0) {
         none = false
     }
 
     setbit(path[:], 0, true)
-    if this.Scope.match(path, 0) {
+    if this.keep(path, 0) {
         none = false
     }
 
-    if none {
+    if none && !(spare(this.root)) {
+        this.indexforget(this.root)
+
         this.root.known = false
         this.root.key = []byte{}
         this.root.values = [][]byte{}
@@ -105,8 +253,6 @@ func (this *collection) Collect() {
     }
 }
 
-// Private methods (collection) (transaction methods)
-
 func (this *collection) confirm() {
     var explore func(*node)
     explore = func(node *node) {
@@ -124,6 +270,10 @@ func (this *collection) confirm() {
 }
 
 func (this *collection) fix() {
+    this.relabel(this.transaction.ongoing)
+}
+
+func (this *collection) relabel(checkpoint bool) {
     var explore func(*node)
     explore = func(node *node) {
         if node.transaction.inconsistent {
@@ -132,6 +282,10 @@ func (this *collection) fix() {
                 explore(node.children.right)
             }
 
+            if checkpoint {
+                node.checkpoint(this.transaction.savepoints)
+            }
+
             this.update(node)
             node.transaction.inconsistent = false
         }
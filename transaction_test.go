@@ -2,6 +2,8 @@ package collection
 
 import "testing"
 import "encoding/binary"
+import "math/rand"
+import "reflect"
 
 func TestTransactionBegin(test *testing.T) {
     ctx := testctx("[transaction.go]", test)
@@ -110,6 +112,61 @@ func TestTransactionRollback(test *testing.T) {
     })
 }
 
+func TestTransactionRollbackConsistency(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 64; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    var inconsistent func(*node) bool
+    inconsistent = func(node *node) bool {
+        if node.transaction.inconsistent {
+            return true
+        }
+
+        if node.leaf() {
+            return false
+        }
+
+        return inconsistent(node.children.left) || inconsistent(node.children.right)
+    }
+
+    collection.Begin()
+
+    collection.Set([]byte{3}, uint64(30))
+    collection.Add([]byte{100}, uint64(100))
+    collection.Remove([]byte{7})
+
+    collection.Rollback()
+
+    if inconsistent(collection.root) {
+        test.Error("[transaction.go]", "[rollback]", "Rollback() leaves nodes marked as inconsistent.")
+    }
+
+    collection.Begin()
+
+    collection.Set([]byte{3}, uint64(30))
+    savepoint := collection.Savepoint()
+    collection.Set([]byte{4}, uint64(40))
+    collection.RollbackTo(savepoint)
+
+    collection.Rollback()
+
+    if inconsistent(collection.root) {
+        test.Error("[transaction.go]", "[rollback]", "Rollback() leaves nodes marked as inconsistent after RollbackTo().")
+    }
+
+    collection.Begin()
+
+    if _, error := collection.Delta(); error != nil {
+        test.Error("[transaction.go]", "[rollback]", "Delta() yields an error in a transaction following Rollback().")
+    }
+
+    collection.End()
+}
+
 func TestTransactionEnd(test *testing.T) {
     ctx := testctx("[transaction.go]", test)
 
@@ -319,3 +376,303 @@ func TestTransactionFix(test *testing.T) {
 
     ctx.verify.tree("[fix]", &collection)
 }
+
+func TestTransactionRelabel(test *testing.T) {
+    ctx := testctx("[transaction.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 64; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    var backups func(*node) int
+    backups = func(node *node) int {
+        count := 0
+
+        for cursor := node.transaction.backup; cursor != nil; cursor = cursor.transaction.backup {
+            count++
+        }
+
+        if !(node.leaf()) {
+            count += backups(node.children.left) + backups(node.children.right)
+        }
+
+        return count
+    }
+
+    collection.Begin()
+    collection.Set([]byte{3}, uint64(30))
+
+    count := backups(collection.root)
+    collection.relabel(false)
+
+    if backups(collection.root) != count {
+        test.Error("[transaction.go]", "[relabel]", "relabel() without checkpoints allocates backups.")
+    }
+
+    ctx.verify.tree("[relabel]", &collection)
+
+    collection.Set([]byte{4}, uint64(40))
+    count = backups(collection.root)
+    collection.fix()
+
+    if backups(collection.root) <= count {
+        test.Error("[transaction.go]", "[relabel]", "fix() does not checkpoint the nodes it relabels during a transaction.")
+    }
+
+    ctx.verify.tree("[relabel]", &collection)
+}
+
+func TestTransactionSavepoints(test *testing.T) {
+    ctx := testctx("[transaction.go]", test)
+
+    stake64 := Stake64{}
+    data := Data{}
+
+    collection := EmptyCollection(stake64, data)
+    collection.Index(1)
+
+    for index := 0; index < 16; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index), []byte("even"))
+    }
+
+    original := collection.root.label
+
+    reference := collection.Clone()
+    reference.Set([]byte{1}, uint64(10), []byte("odd"))
+
+    collection.Begin()
+    collection.Set([]byte{1}, uint64(10), []byte("odd"))
+
+    first := collection.Savepoint()
+
+    collection.Set([]byte{2}, uint64(20), []byte("odd"))
+    collection.Add([]byte{100}, uint64(100), []byte("odd"))
+    collection.Remove([]byte{3})
+
+    second := collection.Savepoint()
+
+    if (first != 1) || (second != 2) {
+        test.Error("[transaction.go]", "[savepoint]", "Savepoint() does not return increasing savepoint numbers.")
+    }
+
+    collection.Set([]byte{1}, uint64(11), []byte("even"))
+    collection.RollbackTo(second)
+
+    ctx.verify.values("[rollbackto]", &collection, []byte{1}, uint64(10), []byte("odd"))
+    ctx.verify.values("[rollbackto]", &collection, []byte{2}, uint64(20), []byte("odd"))
+    ctx.verify.key("[rollbackto]", &collection, []byte{100})
+    ctx.verify.nokey("[rollbackto]", &collection, []byte{3})
+
+    if len(collection.Lookup(1, []byte("odd"))) != 3 {
+        test.Error("[transaction.go]", "[rollbackto]", "RollbackTo() does not restore the index to the savepoint.")
+    }
+
+    collection.Set([]byte{4}, uint64(40), []byte("odd"))
+    collection.RollbackTo(first)
+
+    ctx.verify.values("[rollbackto]", &collection, []byte{1}, uint64(10), []byte("odd"))
+    ctx.verify.values("[rollbackto]", &collection, []byte{2}, uint64(2), []byte("even"))
+    ctx.verify.values("[rollbackto]", &collection, []byte{4}, uint64(4), []byte("even"))
+    ctx.verify.values("[rollbackto]", &collection, []byte{3}, uint64(3), []byte("even"))
+    ctx.verify.nokey("[rollbackto]", &collection, []byte{100})
+
+    if len(collection.Lookup(1, []byte("odd"))) != 1 {
+        test.Error("[transaction.go]", "[rollbackto]", "RollbackTo() does not restore the index to an earlier savepoint.")
+    }
+
+    ctx.should_panic("[rollbackto]", func() {
+        collection.RollbackTo(second)
+    })
+
+    collection.End()
+
+    ctx.verify.tree("[end]", &collection)
+
+    if collection.root.label != reference.root.label {
+        test.Error("[transaction.go]", "[end]", "End() after RollbackTo() yields a wrong root.")
+    }
+
+    collection.Begin()
+    collection.Set([]byte{5}, uint64(50), []byte("odd"))
+
+    first = collection.Savepoint()
+    collection.Set([]byte{5}, uint64(51), []byte("odd"))
+
+    second = collection.Savepoint()
+    collection.Set([]byte{6}, uint64(60), []byte("odd"))
+
+    collection.Release(second)
+
+    ctx.should_panic("[release]", func() {
+        collection.RollbackTo(second)
+    })
+
+    collection.RollbackTo(first)
+
+    ctx.verify.values("[release]", &collection, []byte{5}, uint64(50), []byte("odd"))
+    ctx.verify.values("[release]", &collection, []byte{6}, uint64(6), []byte("even"))
+
+    collection.Set([]byte{7}, uint64(70), []byte("odd"))
+    collection.Release(first)
+
+    if collection.Savepoint() != 1 {
+        test.Error("[transaction.go]", "[release]", "Release() does not discard the savepoint.")
+    }
+
+    collection.Rollback()
+
+    ctx.verify.tree("[rollback]", &collection)
+
+    if collection.root.label != reference.root.label {
+        test.Error("[transaction.go]", "[rollback]", "Rollback() does not restore the collection through savepoints.")
+    }
+
+    if len(collection.Lookup(1, []byte("odd"))) != 1 {
+        test.Error("[transaction.go]", "[rollback]", "Rollback() does not restore the index through savepoints.")
+    }
+
+    collection.Begin()
+    collection.Set([]byte{1}, uint64(1), []byte("even"))
+    collection.Savepoint()
+    collection.Savepoint()
+    collection.End()
+
+    if collection.root.label != original {
+        test.Error("[transaction.go]", "[end]", "End() does not commit changes made before savepoints.")
+    }
+
+    ctx.should_panic("[savepoint]", func() {
+        collection.Savepoint()
+    })
+
+    collection.Begin()
+
+    ctx.should_panic("[release]", func() {
+        collection.Release(1)
+    })
+
+    collection.End()
+}
+
+func TestTransactionChangeSet(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 16; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    before := collection.root.label
+
+    collection.Begin()
+
+    collection.Set([]byte{1}, uint64(10))
+    collection.Set([]byte{2}, uint64(2))
+    collection.Add([]byte{100}, uint64(100))
+    collection.Remove([]byte{3})
+    collection.Add([]byte{101}, uint64(101))
+    collection.Remove([]byte{101})
+
+    changeset := collection.End()
+
+    if (changeset.OldRoot != before) || (changeset.NewRoot != collection.root.label) {
+        test.Error("[transaction.go]", "[changeset]", "End() returns wrong roots in the change set.")
+    }
+
+    if len(changeset.Changes) != 3 {
+        test.Error("[transaction.go]", "[changeset]", "End() does not list exactly the keys affected by the transaction.")
+    }
+
+    for _, change := range(changeset.Changes) {
+        switch {
+        case equal(change.Key, []byte{1}):
+            if change.Added() || change.Removed() || !equal(change.Old[0], stake64.Encode(uint64(1))) || !equal(change.New[0], stake64.Encode(uint64(10))) {
+                test.Error("[transaction.go]", "[changeset]", "End() reports a wrong change for a modified key.")
+            }
+        case equal(change.Key, []byte{100}):
+            if !(change.Added()) || !equal(change.New[0], stake64.Encode(uint64(100))) {
+                test.Error("[transaction.go]", "[changeset]", "End() reports a wrong change for an added key.")
+            }
+        case equal(change.Key, []byte{3}):
+            if !(change.Removed()) || !equal(change.Old[0], stake64.Encode(uint64(3))) {
+                test.Error("[transaction.go]", "[changeset]", "End() reports a wrong change for a removed key.")
+            }
+        default:
+            test.Error("[transaction.go]", "[changeset]", "End() reports a key that was not affected by the transaction.")
+        }
+    }
+
+    collection.Begin()
+    collection.Set([]byte{4}, uint64(40))
+    collection.Set([]byte{4}, uint64(41))
+
+    collection.Savepoint()
+    collection.Set([]byte{4}, uint64(42))
+
+    changeset = collection.End()
+
+    if (len(changeset.Changes) != 1) || !equal(changeset.Changes[0].Old[0], stake64.Encode(uint64(4))) || !equal(changeset.Changes[0].New[0], stake64.Encode(uint64(42))) {
+        test.Error("[transaction.go]", "[changeset]", "End() does not compare against the values before the transaction.")
+    }
+
+    collection.Begin()
+    changeset = collection.End()
+
+    if (len(changeset.Changes) != 0) || (changeset.OldRoot != changeset.NewRoot) {
+        test.Error("[transaction.go]", "[changeset]", "End() returns a non-empty change set for an empty transaction.")
+    }
+}
+
+func TestTransactionSavepointsRandom(test *testing.T) {
+    ctx := testctx("[transaction.go]", test)
+
+    data := Data{}
+    random := rand.New(rand.NewSource(0))
+
+    for run := 0; run < 300; run++ {
+        collection := EmptyCollection(data)
+        collection.Index(0)
+
+        for index := 0; index < 16; index++ {
+            collection.Add([]byte{byte(index)}, []byte{byte(random.Intn(4))})
+        }
+
+        collection.Begin()
+
+        savepoints := 0
+
+        for step := 0; step < 24; step++ {
+            key := []byte{byte(random.Intn(16))}
+            value := []byte{byte(random.Intn(4))}
+
+            switch random.Intn(5) {
+            case 0:
+                collection.Remove(key)
+            case 1:
+                collection.Add(key, value)
+            case 2:
+                collection.Set(key, value)
+            case 3:
+                savepoints = collection.Savepoint()
+            case 4:
+                if savepoints > 0 {
+                    collection.RollbackTo(1 + random.Intn(savepoints))
+                    savepoints = collection.transaction.savepoints
+                }
+            }
+        }
+
+        collection.End()
+        ctx.verify.tree("[random]", &collection)
+
+        reference := collection.Clone()
+
+        if !(reflect.DeepEqual(collection.indexes[0].entries, reference.indexes[0].entries)) {
+            test.Error("[transaction.go]", "[random]", "RollbackTo() leaves the index out of sync with the tree.")
+            break
+        }
+    }
+}
@@ -11,6 +11,10 @@ type userupdate interface {
     Apply(ReadWrite)
 }
 
+type Writer interface {
+    Writes() [][]byte
+}
+
 type ReadOnly interface {
     Get([]byte) Record
 }
@@ -21,6 +25,9 @@ type ReadWrite interface {
     Set([]byte, ... interface{}) error
     SetField([]byte, int, interface{}) error
     Remove([]byte) error
+    Upsert([]byte, ... interface{}) error
+    CompareAndSet([]byte, []interface{}, ... interface{}) error
+    RemoveIf([]byte, func(Record) bool) (bool, error)
 }
 
 // Structs
@@ -29,11 +36,47 @@ type Update struct {
     transaction uint64
     update userupdate
     proxy proxy
+
+    reads [][]byte
+    writes [][]byte
 }
 
 type proxy struct {
     collection *collection
     paths map[[csha256.Size]byte]bool
+    writes map[[csha256.Size]byte]bool
+
+    violation *error
+}
+
+// Update
+
+// Getters
+
+func (this Update) Reads() [][]byte {
+    return this.reads
+}
+
+func (this Update) Writes() [][]byte {
+    return this.writes
+}
+
+// Methods
+
+func (this Update) Conflicts(other Update) bool {
+    for _, write := range(this.writes) {
+        if other.proxy.has(write) {
+            return true
+        }
+    }
+
+    for _, write := range(other.writes) {
+        if this.proxy.has(write) {
+            return true
+        }
+    }
+
+    return false
 }
 
 // proxy
@@ -43,9 +86,12 @@ type proxy struct {
 func (this *collection) proxy(keys [][]byte) (proxy proxy) {
     proxy.collection = this
     proxy.paths = make(map[[csha256.Size]byte]bool)
+    proxy.writes = make(map[[csha256.Size]byte]bool)
+    proxy.violation = new(error)
 
     for index := 0; index < len(keys); index++ {
-        proxy.paths[sha256(keys[index])] = true
+        proxy.paths[this.path(keys[index])] = true
+        proxy.writes[this.path(keys[index])] = true
     }
 
     return
@@ -55,7 +101,8 @@ func (this *collection) proxy(keys [][]byte) (proxy proxy) {
 
 func (this proxy) Get(key []byte) Record {
     if !(this.has(key)) {
-        panic("Accessing undeclared key from update.")
+        this.violate(errors.New("Reading undeclared key from update."))
+        return recordkeymismatch(this.collection, key)
     }
 
     record, _ := this.collection.Get(key).Record()
@@ -63,44 +110,89 @@ func (this proxy) Get(key []byte) Record {
 }
 
 func (this proxy) Add(key []byte, values... interface{}) error {
-    if !(this.has(key)) {
-        panic("Accessing undeclared key from update.")
+    if !(this.writable(key)) {
+        return this.violate(errors.New("Writing undeclared key from update."))
     }
 
     return this.collection.Add(key, values...)
 }
 
 func (this proxy) Set(key []byte, values... interface{}) error {
-    if !(this.has(key)) {
-        panic("Accessing undeclared key from update.")
+    if !(this.writable(key)) {
+        return this.violate(errors.New("Writing undeclared key from update."))
     }
 
     return this.collection.Set(key, values...)
 }
 
 func (this proxy) SetField(key []byte, field int, value interface{}) error {
-    if !(this.has(key)) {
-        panic("Accessing undeclared key from update.")
+    if !(this.writable(key)) {
+        return this.violate(errors.New("Writing undeclared key from update."))
     }
 
     return this.collection.SetField(key, field, value)
 }
 
 func (this proxy) Remove(key []byte) error {
-    if !(this.has(key)) {
-        panic("Accessing undeclared key from update.")
+    if !(this.writable(key)) {
+        return this.violate(errors.New("Writing undeclared key from update."))
     }
 
     return this.collection.Remove(key)
 }
 
+func (this proxy) Upsert(key []byte, values... interface{}) error {
+    if !(this.writable(key)) {
+        return this.violate(errors.New("Writing undeclared key from update."))
+    }
+
+    return this.collection.Upsert(key, values...)
+}
+
+func (this proxy) CompareAndSet(key []byte, expected []interface{}, values... interface{}) error {
+    if !(this.writable(key)) {
+        return this.violate(errors.New("Writing undeclared key from update."))
+    }
+
+    return this.collection.CompareAndSet(key, expected, values...)
+}
+
+func (this proxy) RemoveIf(key []byte, predicate func(Record) bool) (bool, error) {
+    if !(this.writable(key)) {
+        return false, this.violate(errors.New("Writing undeclared key from update."))
+    }
+
+    return this.collection.RemoveIf(key, predicate)
+}
+
 // Private methods
 
 func (this proxy) has(key []byte) bool {
-    path := sha256(key)
+    path := this.collection.path(key)
     return this.paths[path]
 }
 
+func (this proxy) writable(key []byte) bool {
+    path := this.collection.path(key)
+    return this.writes[path]
+}
+
+func (this proxy) violate(violation error) error {
+    if *(this.violation) == nil {
+        *(this.violation) = violation
+    }
+
+    return violation
+}
+
+func (this proxy) failed() error {
+    return *(this.violation)
+}
+
+func (this proxy) reset() {
+    *(this.violation) = nil
+}
+
 // collection
 
 // Methods (collection) (update)
@@ -121,18 +213,40 @@ func (this *collection) Prepare(update userupdate) (Update, error) {
         keys[index] = proofs[index].Key()
     }
 
-    return Update{this.transaction.id, update, this.proxy(keys)}, nil
+    proxy := this.proxy(keys)
+    writes := keys
+
+    writer, declares := update.(Writer)
+
+    if declares {
+        writes = writer.Writes()
+        proxy.writes = make(map[[csha256.Size]byte]bool)
+
+        for index := 0; index < len(writes); index++ {
+            if !(proxy.has(writes[index])) {
+                return Update{}, errors.New("Invalid update: write to undeclared record.")
+            }
+
+            proxy.writes[this.path(writes[index])] = true
+        }
+    }
+
+    return Update{this.transaction.id, update, proxy, keys, writes}, nil
 }
 
 func (this *collection) Apply(object interface{}) error {
     switch update := object.(type) {
     case Update:
         return this.applyupdate(update)
+    case Migration:
+        return this.applymigration(update)
+    case Delta:
+        return this.applydelta(update)
     case userupdate:
         return this.applyuserupdate(update)
     }
 
-    panic("Apply() only accepts Update objects or objects that implement the update interface.")
+    panic("Apply() only accepts Update, Migration or Delta objects, or objects that implement the update interface.")
 }
 
 // Private methods (collection) (update)
@@ -142,19 +256,47 @@ func (this *collection) applyupdate(update Update) error {
         panic("Update was not prepared during the current transaction.")
     }
 
+    update.proxy.reset()
+
     if !(update.update.Check(update.proxy)) {
         return errors.New("Update check failed.")
     }
 
+    error := update.proxy.failed()
+
+    if error != nil {
+        return error
+    }
+
     if this.transaction.ongoing {
+        savepoint := this.Savepoint()
         update.update.Apply(update.proxy)
+
+        error = update.proxy.failed()
+
+        if error != nil {
+            this.RollbackTo(savepoint)
+        }
+
+        this.Release(savepoint)
     } else {
         this.Begin()
         update.update.Apply(update.proxy)
-        this.End()
+
+        error = update.proxy.failed()
+
+        if error != nil {
+            this.Rollback()
+
+            if this.AutoCollect.value {
+                this.Collect()
+            }
+        } else {
+            this.End()
+        }
     }
 
-    return nil
+    return error
 }
 
 func (this *collection) applyuserupdate(update userupdate) error {
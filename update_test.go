@@ -1,6 +1,7 @@
 package collection
 
 import "testing"
+import csha256 "crypto/sha256"
 
 func TestUpdateProxy(test *testing.T) {
     collection := EmptyCollection()
@@ -110,25 +111,96 @@ func TestUpdateProxyMethods(test *testing.T) {
         test.Error("[update.go]", "[remove]", "Proxy method remove() does not yield an error when removing a non-existing key.")
     }
 
-    ctx.should_panic("[get]", func() {
-        proxy.Get([]byte("otherkey"))
-    })
+    error = proxy.Upsert([]byte("secondkey"), uint64(44))
+    if error != nil {
+        test.Error("[update.go]", "[upsert]", "Proxy method upsert() yields an error on a non-existing key.")
+    }
 
-    ctx.should_panic("[add]", func() {
-        proxy.Add([]byte("otherkey"), uint64(12))
-    })
+    error = proxy.CompareAndSet([]byte("secondkey"), []interface{}{uint64(44)}, uint64(45))
+    if error != nil {
+        test.Error("[update.go]", "[compareandset]", "Proxy method compareandset() yields an error on matching values.")
+    }
 
-    ctx.should_panic("[set]", func() {
-        proxy.Set([]byte("otherkey"), uint64(12))
-    })
+    record, _ = collection.Get([]byte("secondkey")).Record()
+    values, _ = record.Values()
 
-    ctx.should_panic("[setfield]", func() {
-        proxy.SetField([]byte("otherkey"), 0, uint64(12))
-    })
+    if values[0].(uint64) != 45 {
+        test.Error("[update.go]", "[compareandset]", "Proxy method compareandset() does not set the correct values.")
+    }
 
-    ctx.should_panic("[remove]", func() {
-        proxy.Remove([]byte("otherkey"))
-    })
+    removed, error := proxy.RemoveIf([]byte("secondkey"), func(record Record) bool { return true })
+    if !removed || (error != nil) {
+        test.Error("[update.go]", "[removeif]", "Proxy method removeif() does not remove a matching key.")
+    }
+
+    if proxy.failed() != nil {
+        test.Error("[update.go]", "[violation]", "Proxy records a violation on declared keys.")
+    }
+
+    if proxy.Get([]byte("otherkey")).Match() || (proxy.failed() == nil) {
+        test.Error("[update.go]", "[get]", "Proxy method get() does not record a violation on an undeclared key.")
+    }
+
+    proxy.reset()
+
+    if proxy.failed() != nil {
+        test.Error("[update.go]", "[reset]", "Proxy method reset() does not clear the violation.")
+    }
+
+    if proxy.Add([]byte("otherkey"), uint64(12)) == nil {
+        test.Error("[update.go]", "[add]", "Proxy method add() does not yield an error on an undeclared key.")
+    }
+
+    if proxy.Set([]byte("otherkey"), uint64(12)) == nil {
+        test.Error("[update.go]", "[set]", "Proxy method set() does not yield an error on an undeclared key.")
+    }
+
+    if proxy.SetField([]byte("otherkey"), 0, uint64(12)) == nil {
+        test.Error("[update.go]", "[setfield]", "Proxy method setfield() does not yield an error on an undeclared key.")
+    }
+
+    if proxy.Remove([]byte("otherkey")) == nil {
+        test.Error("[update.go]", "[remove]", "Proxy method remove() does not yield an error on an undeclared key.")
+    }
+
+    if proxy.Upsert([]byte("otherkey"), uint64(12)) == nil {
+        test.Error("[update.go]", "[upsert]", "Proxy method upsert() does not yield an error on an undeclared key.")
+    }
+
+    if proxy.CompareAndSet([]byte("otherkey"), nil, uint64(12)) == nil {
+        test.Error("[update.go]", "[compareandset]", "Proxy method compareandset() does not yield an error on an undeclared key.")
+    }
+
+    if _, error := proxy.RemoveIf([]byte("otherkey"), func(record Record) bool { return true }); error == nil {
+        test.Error("[update.go]", "[removeif]", "Proxy method removeif() does not yield an error on an undeclared key.")
+    }
+
+    if proxy.failed() == nil {
+        test.Error("[update.go]", "[violation]", "Proxy does not record write violations.")
+    }
+
+    ctx.verify.nokey("[violation]", &collection, []byte("otherkey"))
+
+    proxy.writes = map[[csha256.Size]byte]bool{sha256([]byte("firstkey")): true}
+    proxy.reset()
+
+    proxy.Get([]byte("thirdkey"))
+
+    if proxy.failed() != nil {
+        test.Error("[update.go]", "[get]", "Proxy method get() records a violation on a read-only key.")
+    }
+
+    if proxy.SetField([]byte("firstkey"), 0, uint64(12)) != nil {
+        test.Error("[update.go]", "[writable]", "Proxy method setfield() yields an error on a writable key.")
+    }
+
+    proxy.Add([]byte("thirdkey"), uint64(1))
+
+    if proxy.failed() == nil {
+        test.Error("[update.go]", "[writable]", "Proxy does not record a violation when writing a read-only key.")
+    }
+
+    ctx.verify.nokey("[writable]", &collection, []byte("thirdkey"))
 }
 
 func TestUpdateProxyHas(test *testing.T) {
@@ -474,3 +546,129 @@ func TestUpdateApplyUserUpdate(test *testing.T) {
         collection.End()
     })
 }
+
+type TestUpdateDeclaredUpdate struct {
+    from Proof
+    to Proof
+    writes [][]byte
+}
+
+func (this TestUpdateDeclaredUpdate) Records() []Proof {
+    return []Proof{this.from, this.to}
+}
+
+func (this TestUpdateDeclaredUpdate) Writes() [][]byte {
+    return this.writes
+}
+
+func (this TestUpdateDeclaredUpdate) Check(collection ReadOnly) bool {
+    return collection.Get(this.from.Key()).Match() && collection.Get(this.to.Key()).Match()
+}
+
+func (this TestUpdateDeclaredUpdate) Apply(collection ReadWrite) {
+    values, _ := collection.Get(this.to.Key()).Values()
+    collection.Set(this.to.Key(), values[0].(uint64) + 1)
+
+    values, _ = collection.Get(this.from.Key()).Values()
+    collection.Set(this.from.Key(), values[0].(uint64) - 1)
+}
+
+func TestUpdateWrites(test *testing.T) {
+    ctx := testctx("[update.go]", test)
+
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    collection.Add([]byte("alice"), uint64(5))
+    collection.Add([]byte("bob"), uint64(0))
+    collection.Add([]byte("carol"), uint64(0))
+
+    alice, _ := collection.Get([]byte("alice")).Proof()
+    bob, _ := collection.Get([]byte("bob")).Proof()
+    carol, _ := collection.Get([]byte("carol")).Proof()
+
+    _, error := collection.Prepare(TestUpdateDeclaredUpdate{alice, bob, [][]byte{[]byte("carol")}})
+
+    if error == nil {
+        test.Error("[update.go]", "[prepare]", "Prepare() does not yield an error when writes are not a subset of the records.")
+    }
+
+    readonly, _ := collection.Prepare(TestUpdateDeclaredUpdate{alice, bob, [][]byte{[]byte("bob")}})
+
+    if (len(readonly.Reads()) != 2) || (len(readonly.Writes()) != 1) || !(equal(readonly.Writes()[0], []byte("bob"))) {
+        test.Error("[update.go]", "[prepare]", "Prepare() does not set the declared reads and writes.")
+    }
+
+    undeclared, _ := collection.Prepare(TestUpdateSingleRecordUpdate{carol})
+
+    if (len(undeclared.Writes()) != 1) || !(equal(undeclared.Writes()[0], []byte("carol"))) {
+        test.Error("[update.go]", "[prepare]", "Prepare() does not allow writes to every record of an update without declared writes.")
+    }
+
+    if collection.Apply(readonly) == nil {
+        test.Error("[update.go]", "[apply]", "Apply() does not yield an error when an update writes a read-only key.")
+    }
+
+    ctx.verify.values("[apply]", &collection, []byte("alice"), uint64(5))
+    ctx.verify.values("[apply]", &collection, []byte("bob"), uint64(0))
+
+    transfer, _ := collection.Prepare(TestUpdateDeclaredUpdate{alice, bob, [][]byte{[]byte("alice"), []byte("bob")}})
+
+    if collection.Apply(transfer) != nil {
+        test.Error("[update.go]", "[apply]", "Apply() yields an error when an update writes declared keys.")
+    }
+
+    ctx.verify.values("[apply]", &collection, []byte("alice"), uint64(4))
+    ctx.verify.values("[apply]", &collection, []byte("bob"), uint64(1))
+
+    alice, _ = collection.Get([]byte("alice")).Proof()
+    bob, _ = collection.Get([]byte("bob")).Proof()
+    carol, _ = collection.Get([]byte("carol")).Proof()
+
+    collection.Begin()
+
+    transfer, _ = collection.Prepare(TestUpdateDeclaredUpdate{alice, bob, [][]byte{[]byte("alice"), []byte("bob")}})
+    readonly, _ = collection.Prepare(TestUpdateDeclaredUpdate{alice, carol, [][]byte{[]byte("carol")}})
+
+    collection.Apply(transfer)
+
+    if collection.Apply(readonly) == nil {
+        test.Error("[update.go]", "[transaction]", "Apply() does not yield an error on a violation inside a transaction.")
+    }
+
+    collection.End()
+
+    ctx.verify.values("[transaction]", &collection, []byte("alice"), uint64(3))
+    ctx.verify.values("[transaction]", &collection, []byte("bob"), uint64(2))
+    ctx.verify.values("[transaction]", &collection, []byte("carol"), uint64(0))
+    ctx.verify.tree("[transaction]", &collection)
+}
+
+func TestUpdateConflicts(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    collection.Add([]byte("alice"), uint64(5))
+    collection.Add([]byte("bob"), uint64(5))
+    collection.Add([]byte("carol"), uint64(5))
+
+    alice, _ := collection.Get([]byte("alice")).Proof()
+    bob, _ := collection.Get([]byte("bob")).Proof()
+    carol, _ := collection.Get([]byte("carol")).Proof()
+
+    first, _ := collection.Prepare(TestUpdateDeclaredUpdate{bob, alice, [][]byte{[]byte("alice")}})
+    second, _ := collection.Prepare(TestUpdateDeclaredUpdate{bob, carol, [][]byte{[]byte("carol")}})
+    third, _ := collection.Prepare(TestUpdateDeclaredUpdate{carol, alice, [][]byte{[]byte("carol")}})
+
+    if first.Conflicts(second) || second.Conflicts(first) {
+        test.Error("[update.go]", "[conflicts]", "Conflicts() reports a conflict between updates sharing only reads.")
+    }
+
+    if !(first.Conflicts(third)) || !(third.Conflicts(first)) {
+        test.Error("[update.go]", "[conflicts]", "Conflicts() does not report a write to a key read by the other update.")
+    }
+
+    if !(second.Conflicts(third)) {
+        test.Error("[update.go]", "[conflicts]", "Conflicts() does not report overlapping writes.")
+    }
+}
@@ -1,5 +1,8 @@
 package collection
 
+import "errors"
+import csha256 "crypto/sha256"
+
 // Methods (collection) (verifiers)
 
 func (this *collection) Verify(proof Proof) bool {
@@ -7,7 +10,11 @@ func (this *collection) Verify(proof Proof) bool {
         panic("Verify() called on inconsistent root.")
     }
 
-    if (proof.root.Label != this.root.label) || !(proof.consistent()) {
+    if proof.root.Label != this.root.label {
+        return false
+    }
+
+    if (this.wellformed(proof) != nil) || !(proof.consistent()) {
         return false
     }
 
@@ -15,16 +22,18 @@ func (this *collection) Verify(proof Proof) bool {
         proof.root.to(this.root)
     }
 
-    path := sha256(proof.key)
+    path := this.path(proof.key)
     cursor := this.root
 
     for depth := 0; depth < len(proof.steps); depth++ {
         if !(cursor.children.left.known) {
             proof.steps[depth].Left.to(cursor.children.left)
+            this.indexlearn(cursor.children.left)
         }
 
         if !(cursor.children.right.known) {
             proof.steps[depth].Right.to(cursor.children.right)
+            this.indexlearn(cursor.children.right)
         }
 
         if bit(path[:], depth) {
@@ -34,5 +43,145 @@ func (this *collection) Verify(proof Proof) bool {
         }
     }
 
+    this.touch(cursor)
+
+    if this.Cache.enabled() {
+        this.evict()
+    }
+
     return true
 }
+
+// Private methods (collection) (verifiers)
+
+func (this *collection) validate(values [][]byte) error {
+    if len(values) != len(this.fields) {
+        return errors.New("Wrong number of values.")
+    }
+
+    for index := 0; index < len(this.fields); index++ {
+        validator, validates := this.fields[index].(Validator)
+
+        if validates {
+            error := validator.Validate(values[index])
+
+            if error != nil {
+                return error
+            }
+        }
+    }
+
+    return nil
+}
+
+func (this *collection) wellformed(proof Proof) error {
+    if len(proof.steps) > 8 * csha256.Size {
+        return errors.New("Proof has more steps than path bits.")
+    }
+
+    if this.Limits.long(len(proof.steps)) || this.Limits.deep(len(proof.steps)) {
+        return errors.New("Proof exceeds the maximum number of steps.")
+    }
+
+    error := this.validate(proof.root.Values)
+
+    if error != nil {
+        return error
+    }
+
+    for index := 0; index < len(proof.steps); index++ {
+        lefterror := this.validate(proof.steps[index].Left.Values)
+
+        if lefterror != nil {
+            return lefterror
+        }
+
+        righterror := this.validate(proof.steps[index].Right.Values)
+
+        if righterror != nil {
+            return righterror
+        }
+    }
+
+    if this.Strict.value {
+        return this.strict(proof)
+    }
+
+    return nil
+}
+
+func (this *collection) strict(proof Proof) error {
+    path := this.path(proof.key)
+
+    placeholder := make([][]byte, len(this.fields))
+    for index := 0; index < len(this.fields); index++ {
+        placeholder[index] = this.fields[index].Placeholder()
+    }
+
+    position := func(dump *dump, depth int, side bool) error {
+        if !(dump.leaf()) {
+            return nil
+        }
+
+        if len(dump.Key) == 0 {
+            for index := 0; index < len(this.fields); index++ {
+                if !(equal(dump.Values[index], placeholder[index])) {
+                    return errors.New("Placeholder has wrong values.")
+                }
+            }
+
+            return nil
+        }
+
+        keypath := this.path(dump.Key)
+
+        if !(match(keypath[:], path[:], depth)) || (bit(keypath[:], depth) != side) {
+            return errors.New("Leaf lies on the wrong path.")
+        }
+
+        return nil
+    }
+
+    cursor := &(proof.root)
+
+    for depth := 0; depth < len(proof.steps); depth++ {
+        left := &(proof.steps[depth].Left)
+        right := &(proof.steps[depth].Right)
+
+        if (depth > 0) && left.leaf() && right.leaf() && ((len(left.Key) == 0) || (len(right.Key) == 0)) {
+            return errors.New("Proof is not in canonical form.")
+        }
+
+        for index := 0; index < len(this.fields); index++ {
+            parentvalue, parenterror := this.fields[index].Parent(left.Values[index], right.Values[index])
+
+            if parenterror != nil {
+                return parenterror
+            }
+
+            if !(equal(parentvalue, cursor.Values[index])) {
+                return errors.New("Node values do not match its children.")
+            }
+        }
+
+        lefterror := position(left, depth, false)
+
+        if lefterror != nil {
+            return lefterror
+        }
+
+        righterror := position(right, depth, true)
+
+        if righterror != nil {
+            return righterror
+        }
+
+        if bit(path[:], depth) {
+            cursor = right
+        } else {
+            cursor = left
+        }
+    }
+
+    return nil
+}
@@ -66,3 +66,218 @@ func TestVerifiersVerify(test *testing.T) {
         collection.Verify(proof)
     });
 }
+
+type TestVerifiersShortStake64 struct {
+    Stake64
+}
+
+func (this TestVerifiersShortStake64) Encode(generic interface{}) []byte {
+    return []byte{1, 2, 3}
+}
+
+func TestVerifiersValidate(test *testing.T) {
+    stake64 := Stake64{}
+    data := Data{}
+
+    forged := EmptyCollection(data)
+    forged.Add([]byte("mykey"), []byte{1, 2, 3})
+
+    verifier := EmptyVerifier(stake64)
+    verifier.root.label = forged.root.label
+
+    proof, _ := forged.Get([]byte("mykey")).Proof()
+
+    if verifier.Verify(proof) {
+        test.Error("[verifiers.go]", "[validate]", "Verify() accepts a proof with malformed values.")
+    }
+
+    if verifier.root.known {
+        test.Error("[verifiers.go]", "[validate]", "Verify() installs nodes from a proof with malformed values.")
+    }
+
+    _, error := verifier.Deserialize(forged.Serialize(proof))
+
+    if error == nil {
+        test.Error("[verifiers.go]", "[validate]", "Deserialize() does not yield an error on a proof with malformed values.")
+    }
+
+    wrongcount := EmptyVerifier(stake64, data)
+    wrongcount.root.label = forged.root.label
+
+    if wrongcount.Verify(proof) {
+        test.Error("[verifiers.go]", "[validate]", "Verify() accepts a proof with the wrong number of values.")
+    }
+
+    short := TestVerifiersShortStake64{}
+    collection := EmptyCollection(stake64, short)
+
+    if collection.Add([]byte("mykey"), uint64(1), uint64(2)) == nil {
+        test.Error("[verifiers.go]", "[validate]", "Add() does not yield an error on malformed values.")
+    }
+
+    record, _ := collection.Get([]byte("mykey")).Record()
+
+    if record.Match() {
+        test.Error("[verifiers.go]", "[validate]", "Add() stores malformed values.")
+    }
+
+    collection = EmptyCollection(stake64, data)
+    collection.Add([]byte("mykey"), uint64(1), []byte("myvalue"))
+
+    if collection.validate([][]byte{make([]byte, 8), []byte{}}) != nil {
+        test.Error("[verifiers.go]", "[validate]", "validate() rejects well-formed values.")
+    }
+
+    if collection.validate([][]byte{make([]byte, 7), []byte{}}) == nil {
+        test.Error("[verifiers.go]", "[validate]", "validate() accepts malformed values.")
+    }
+
+    shortcollection := EmptyCollection(short)
+    shortcollection.fields[0] = stake64
+    shortcollection.Add([]byte("mykey"), uint64(1))
+    shortcollection.fields[0] = short
+
+    if shortcollection.Set([]byte("mykey"), uint64(2)) == nil {
+        test.Error("[verifiers.go]", "[validate]", "Set() does not yield an error on malformed values.")
+    }
+
+    record, _ = shortcollection.Get([]byte("mykey")).Record()
+
+    if !equal(record.values[0], stake64.Encode(uint64(1))) {
+        test.Error("[verifiers.go]", "[validate]", "Set() stores malformed values.")
+    }
+}
+
+func TestVerifiersStrict(test *testing.T) {
+    stake64 := Stake64{}
+    data := Data{}
+
+    verify := func(collection *collection, key []byte) (bool, bool) {
+        proof, _ := collection.Get(key).Proof()
+
+        loose := EmptyVerifier(stake64)
+        loose.root.label = collection.root.label
+
+        strict := EmptyVerifier(stake64)
+        strict.root.label = collection.root.label
+        strict.Strict.Enable()
+
+        return loose.Verify(proof), strict.Verify(proof)
+    }
+
+    reference := EmptyCollection(stake64, data)
+
+    for index := 0; index < 512; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        reference.Add(key, uint64(index), key)
+    }
+
+    verifier := EmptyVerifier(stake64, data)
+    verifier.root.label = reference.root.label
+    verifier.Strict.Enable()
+
+    for index := 0; index < 1024; index++ {
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, uint64(index))
+
+        proof, _ := reference.Get(key).Proof()
+
+        if !(verifier.Verify(proof)) {
+            test.Error("[verifiers.go]", "[strict]", "Strict Verify() rejects a valid proof.")
+        }
+    }
+
+    swapped := EmptyCollection(stake64)
+
+    for index := 0; index < 16; index++ {
+        swapped.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    swapped.root.children.left, swapped.root.children.right = swapped.root.children.right, swapped.root.children.left
+    swapped.update(swapped.root)
+
+    for index := 0; index < 16; index++ {
+        loose, strict := verify(&swapped, []byte{byte(index)})
+
+        if !loose {
+            test.Error("[verifiers.go]", "[strict]", "Forged proof with swapped subtrees is rejected without strict mode.")
+        }
+
+        if strict {
+            test.Error("[verifiers.go]", "[strict]", "Strict Verify() accepts a proof with leaves on the wrong path.")
+        }
+    }
+
+    single := func() (single collection) {
+        single = EmptyCollection(stake64)
+        single.Add([]byte("alice"), uint64(1))
+
+        return
+    }
+
+    path := sha256([]byte("alice"))
+
+    deep := single()
+    leaf := deep.root.children.left
+    if bit(path[:], 0) {
+        leaf = deep.root.children.right
+    }
+
+    key := leaf.key
+    values := leaf.values
+
+    leaf.branch()
+    leaf.key = []byte{}
+
+    if bit(path[:], 1) {
+        deep.placeholder(leaf.children.left)
+        leaf = leaf.children.right
+    } else {
+        deep.placeholder(leaf.children.right)
+        leaf = leaf.children.left
+    }
+
+    leaf.known = true
+    leaf.key = key
+    leaf.values = values
+
+    for cursor := leaf; cursor != nil; cursor = cursor.parent {
+        deep.update(cursor)
+    }
+
+    if loose, strict := verify(&deep, []byte("alice")); !loose || strict {
+        test.Error("[verifiers.go]", "[strict]", "Strict Verify() accepts a proof that is not in canonical form.")
+    }
+
+    aggregated := single()
+    aggregated.root.values[0] = stake64.Encode(uint64(999))
+    aggregated.root.label = sha256(false, aggregated.root.values, aggregated.root.children.left.label[:], aggregated.root.children.right.label[:])
+
+    if loose, strict := verify(&aggregated, []byte("alice")); !loose || strict {
+        test.Error("[verifiers.go]", "[strict]", "Strict Verify() accepts a proof with wrong aggregated values.")
+    }
+
+    placeholder := single()
+    empty := placeholder.root.children.right
+    if bit(path[:], 0) {
+        empty = placeholder.root.children.left
+    }
+
+    empty.values[0] = stake64.Encode(uint64(5))
+    placeholder.update(empty)
+    placeholder.update(placeholder.root)
+
+    if loose, strict := verify(&placeholder, []byte("alice")); !loose || strict {
+        test.Error("[verifiers.go]", "[strict]", "Strict Verify() accepts a proof with a wrong placeholder.")
+    }
+
+    proof, _ := swapped.Get([]byte{3}).Proof()
+    verifier = EmptyVerifier(stake64)
+    verifier.Strict.Enable()
+
+    if _, error := verifier.Deserialize(swapped.Serialize(proof)); error == nil {
+        test.Error("[verifiers.go]", "[strict]", "Strict Deserialize() accepts a proof with leaves on the wrong path.")
+    }
+}
@@ -0,0 +1,94 @@
+package collection
+
+import "errors"
+
+// view
+
+type view struct {
+    collection *collection
+}
+
+// Constructors
+
+func (this *collection) View() view {
+    return view{this}
+}
+
+// Methods
+
+func (this view) Get(key []byte) viewgetter {
+    return viewgetter{this.collection, key}
+}
+
+// viewgetter
+
+type viewgetter struct {
+    collection *collection
+    key []byte
+}
+
+// Methods
+
+func (this viewgetter) Record() (Record, error) {
+    return this.collection.committedrecord(this.key)
+}
+
+func (this viewgetter) Proof() (Proof, error) {
+    if !(this.collection.transaction.ongoing) {
+        error := this.collection.fetch(this.key)
+
+        if error != nil {
+            return Proof{}, error
+        }
+    }
+
+    return this.collection.committedproof(this.key)
+}
+
+// collection
+
+// Private methods (collection) (view)
+
+func (this *collection) uncommitted() bool {
+    return this.transaction.ongoing && (this.root.transaction.inconsistent || (this.root.transaction.backup != nil))
+}
+
+func (this *collection) committedrecord(key []byte) (Record, error) {
+    if !(this.transaction.ongoing) {
+        error := this.fetch(key)
+
+        if error != nil {
+            return Record{}, error
+        }
+    }
+
+    path := this.path(key)
+
+    depth := 0
+    cursor := this.root.committed()
+
+    for {
+        if !(cursor.known) {
+            return Record{}, errors.New("Record lies in an unknown subtree.")
+        }
+
+        if cursor.leaf() {
+            if !(equal(cursor.key, key)) {
+                return recordkeymismatch(this, key), nil
+            }
+
+            values := make([][]byte, len(cursor.values))
+            copy(values, cursor.values)
+
+            return Record{this, 0, []byte{}, true, cursor.key, values}, nil
+        }
+
+        if bit(path[:], depth) {
+            cursor = cursor.children.right.committed()
+        } else {
+            cursor = cursor.children.left.committed()
+        }
+
+        depth++
+    }
+}
@@ -0,0 +1,127 @@
+package collection
+
+import "testing"
+
+func TestViewRecord(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    collection.Add([]byte("alice"), uint64(10))
+    collection.Add([]byte("bob"), uint64(20))
+
+    record, error := collection.View().Get([]byte("alice")).Record()
+    values, _ := record.Values()
+
+    if (error != nil) || !(record.Match()) || (values[0].(uint64) != 10) {
+        test.Error("[view.go]", "[record]", "Record() does not return committed values outside of a transaction.")
+    }
+
+    collection.Begin()
+
+    collection.Set([]byte("alice"), uint64(11))
+    collection.Add([]byte("carol"), uint64(30))
+    collection.Remove([]byte("bob"))
+
+    record, _ = collection.Get([]byte("alice")).Record()
+    values, _ = record.Values()
+
+    if values[0].(uint64) != 11 {
+        test.Error("[view.go]", "[record]", "Get() does not return uncommitted values inside a transaction.")
+    }
+
+    record, _ = collection.View().Get([]byte("alice")).Record()
+    values, _ = record.Values()
+
+    if values[0].(uint64) != 10 {
+        test.Error("[view.go]", "[record]", "Record() returns uncommitted values.")
+    }
+
+    record, _ = collection.View().Get([]byte("carol")).Record()
+
+    if record.Match() {
+        test.Error("[view.go]", "[record]", "Record() finds a key added by the ongoing transaction.")
+    }
+
+    record, _ = collection.View().Get([]byte("bob")).Record()
+
+    if !(record.Match()) {
+        test.Error("[view.go]", "[record]", "Record() misses a key removed by the ongoing transaction.")
+    }
+
+    collection.End()
+
+    record, _ = collection.View().Get([]byte("alice")).Record()
+    values, _ = record.Values()
+
+    if values[0].(uint64) != 11 {
+        test.Error("[view.go]", "[record]", "Record() does not return values committed by End().")
+    }
+
+    verifier := EmptyVerifier(stake64)
+
+    if _, error := verifier.View().Get([]byte("alice")).Record(); error == nil {
+        test.Error("[view.go]", "[record]", "Record() does not yield an error on an unknown subtree.")
+    }
+}
+
+func TestViewProof(test *testing.T) {
+    stake64 := Stake64{}
+    collection := EmptyCollection(stake64)
+
+    for index := 0; index < 16; index++ {
+        collection.Add([]byte{byte(index)}, uint64(index))
+    }
+
+    committed := collection.root.label
+
+    collection.Begin()
+
+    if _, error := collection.Get([]byte{3}).Proof(); error != nil {
+        test.Error("[view.go]", "[proof]", "Proof() yields an error in a transaction without changes.")
+    }
+
+    collection.Set([]byte{3}, uint64(33))
+    collection.Add([]byte{16}, uint64(16))
+
+    if _, error := collection.Get([]byte{3}).Proof(); error == nil {
+        test.Error("[view.go]", "[proof]", "Proof() does not yield an error on uncommitted data.")
+    }
+
+    if _, error := collection.Get([]byte{5}).Proof(); error == nil {
+        test.Error("[view.go]", "[proof]", "Proof() does not yield an error on a stale root.")
+    }
+
+    verifier := EmptyVerifier(stake64)
+    verifier.root.label = committed
+
+    for _, key := range([][]byte{{3}, {5}, {16}}) {
+        proof, error := collection.View().Get(key).Proof()
+
+        if error != nil {
+            test.Error("[view.go]", "[proof]", "Proof() yields an error on committed data.")
+        }
+
+        if !(verifier.Verify(proof)) {
+            test.Error("[view.go]", "[proof]", "Proof() does not prove the committed state.")
+        }
+    }
+
+    proof, _ := collection.View().Get([]byte{3}).Proof()
+    values, _ := proof.Values()
+
+    if !(proof.Match()) || (values[0].(uint64) != 3) {
+        test.Error("[view.go]", "[proof]", "Proof() does not carry the committed values.")
+    }
+
+    proof, _ = collection.View().Get([]byte{16}).Proof()
+
+    if proof.Match() {
+        test.Error("[view.go]", "[proof]", "Proof() proves a key added by the ongoing transaction.")
+    }
+
+    collection.End()
+
+    if _, error := collection.Get([]byte{3}).Proof(); error != nil {
+        test.Error("[view.go]", "[proof]", "Proof() yields an error after End().")
+    }
+}